@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyHeader is the request header machine clients send their key in.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyScheme starts every key so leaked keys are easy to recognise and
+// scan for.
+const apiKeyScheme = "gms"
+
+// NewAPIKey generates a key of the form gms_<prefix>_<secret>. The prefix is
+// stored in the clear to look the key up; the secret has 256 bits of
+// entropy, so a fast hash is enough to protect it at rest.
+func NewAPIKey() (key string, prefix string, err error) {
+	prefixBytes := make([]byte, 6)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+	return key, prefix, nil
+}
+
+// APIKeyPrefix returns the lookup prefix of key, or false if key is not
+// shaped like one NewAPIKey makes.
+func APIKeyPrefix(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// HashAPIKey is the hash a key is stored as.
+func HashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// MatchAPIKey reports, in constant time, whether key has hash.
+func MatchAPIKey(key string, hash []byte) bool {
+	return subtle.ConstantTimeCompare(HashAPIKey(key), hash) == 1
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "strings"
+
+// Allows reports whether the granted permissions include required, e.g.
+// products:write. A granted * matches any resource or action, so *:* allows
+// everything.
+func Allows(granted []string, required string) bool {
+	resource, action, _ := strings.Cut(required, ":")
+	for _, permission := range granted {
+		grantedResource, grantedAction, _ := strings.Cut(permission, ":")
+		if (grantedResource == "*" || grantedResource == resource) && (grantedAction == "*" || grantedAction == action) {
+			return true
+		}
+	}
+	return false
+}
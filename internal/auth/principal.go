@@ -0,0 +1,36 @@
+// Package auth verifies bearer tokens and API keys and carries the caller
+// they identify through the request context.
+package auth
+
+import (
+	"context"
+)
+
+// Principal is the verified caller of a request, identified by a bearer
+// token or an API key.
+type Principal struct {
+	// Subject is the sub claim, the caller's ID at the issuer
+	Subject string
+	Issuer  string
+	// Scopes come from the space-separated scope claim or the scp array
+	Scopes []string
+	// Claims holds every claim of the token for callers that need more
+	Claims map[string]interface{}
+	// Permissions are set for API keys, which hold only those they were
+	// issued with. Callers with a token get theirs from their roles.
+	Permissions []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal ctx carries, if any. Requests to
+// public routes, or made while authentication is off, have none.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
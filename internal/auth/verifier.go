@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/johnifegwu/go-microservices/internal/config"
+)
+
+// signingMethods are the algorithms tokens may be signed with. Anything
+// else, none and HS256 included, is rejected before a key is looked up.
+var signingMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+// ErrInvalidToken wraps every reason a token is refused.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier checks bearer tokens against the issuer's signing keys.
+type Verifier struct {
+	keys   keyfunc.Keyfunc
+	parser *jwt.Parser
+}
+
+// NewVerifier loads the key set cfg names. Keys read from a URL are fetched
+// again every cfg.JWKSRefresh, and when a token names a key not seen yet,
+// until ctx is done.
+func NewVerifier(ctx context.Context, cfg config.Auth) (*Verifier, error) {
+	var keys keyfunc.Keyfunc
+	var err error
+	if cfg.JWKSFile != "" {
+		var raw []byte
+		raw, err = os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS file: %w", err)
+		}
+		keys, err = keyfunc.NewJWKSetJSON(raw)
+	} else {
+		keys, err = keyfunc.NewDefaultOverrideCtx(ctx, []string{cfg.JWKSURL}, keyfunc.Override{RefreshInterval: cfg.JWKSRefresh})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("JWKS: %w", err)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// Verify checks the signature and claims of token and returns the caller it
+// identifies. Every failure wraps ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keys.KeyfuncCtx(ctx)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: the token has no subject", ErrInvalidToken)
+	}
+	issuer, _ := claims.GetIssuer()
+
+	return &Principal{
+		Subject: subject,
+		Issuer:  issuer,
+		Scopes:  scopes(claims),
+		Claims:  claims,
+	}, nil
+}
+
+// scopes reads the OAuth 2.0 scope claim, a space-separated string, or the
+// scp array some issuers send instead.
+func scopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	var scopes []string
+	if scp, ok := claims["scp"].([]interface{}); ok {
+		for _, scope := range scp {
+			if scope, ok := scope.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/johnifegwu/go-microservices/internal/config"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com/"
+	testAudience = "wisdom-api"
+	testKeyID    = "test-key"
+)
+
+// Only tokens signed by the issuer's key with RS256 or ES256, from the
+// configured issuer, for the configured audience and still current are
+// accepted; everything else is refused as ErrInvalidToken.
+func TestVerify(t *testing.T) {
+	key := newRSAKey(t)
+	verifier := newTestVerifier(t, key)
+	now := time.Now()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   testIssuer,
+			"aud":   testAudience,
+			"sub":   "auth0|123",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "products:read products:write",
+		}
+	}
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		invalid string
+	}{
+		{name: "valid", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, valid())},
+		{name: "audience among several", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("aud", []string{"other", testAudience}))},
+		{name: "wrong issuer", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("iss", "https://evil.example.com/")), invalid: "issuer"},
+		{name: "no issuer", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("iss", nil)), invalid: "iss claim is required"},
+		{name: "wrong audience", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("aud", "other-api")), invalid: "audience"},
+		{name: "no audience", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("aud", nil)), invalid: "aud claim is required"},
+		{name: "expired", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("exp", now.Add(-time.Hour).Unix())), invalid: "expired"},
+		{name: "no expiry", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("exp", nil)), invalid: "exp claim is required"},
+		{name: "not yet valid", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("nbf", now.Add(time.Hour).Unix())), invalid: "not valid yet"},
+		{name: "issued in the future", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("iat", now.Add(time.Hour).Unix())), invalid: "used before issued"},
+		{name: "no subject", token: sign(t, jwt.SigningMethodRS256, key, testKeyID, with("sub", nil)), invalid: "no subject"},
+		{name: "alg none", token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testKeyID, valid()), invalid: "signing method none is invalid"},
+		{name: "alg HS256", token: sign(t, jwt.SigningMethodHS256, []byte("secret"), testKeyID, valid()), invalid: "signing method HS256 is invalid"},
+		{name: "other key", token: sign(t, jwt.SigningMethodRS256, newRSAKey(t), testKeyID, valid()), invalid: "verification error"},
+		{name: "unknown key ID", token: sign(t, jwt.SigningMethodRS256, key, "other-key", valid()), invalid: "key not found"},
+		{name: "tampered", token: tamper(t, sign(t, jwt.SigningMethodRS256, key, testKeyID, valid())), invalid: "verification error"},
+		{name: "malformed", token: "not.a.token", invalid: "malformed"},
+		{name: "empty", token: "", invalid: "malformed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal, err := verifier.Verify(context.Background(), test.token)
+
+			if test.invalid != "" {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify = %v, want ErrInvalidToken", err)
+				}
+				if !strings.Contains(err.Error(), test.invalid) {
+					t.Errorf("Verify = %v, want it to mention %q", err, test.invalid)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Verify = %v", err)
+			}
+			if principal.Subject != "auth0|123" || principal.Issuer != testIssuer {
+				t.Errorf("principal = %s from %s, want auth0|123 from %s", principal.Subject, principal.Issuer, testIssuer)
+			}
+			if !slices.Equal(principal.Scopes, []string{"products:read", "products:write"}) {
+				t.Errorf("scopes = %q", principal.Scopes)
+			}
+		})
+	}
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestVerifier trusts the public half of key, published under
+// testKeyID in a JWKS file.
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) *Verifier {
+	t.Helper()
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"alg": jwt.SigningMethodRS256.Alg(),
+			"use": "sig",
+			"n":   encode(key.N),
+			"e":   encode(big.NewInt(int64(key.E))),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := NewVerifier(context.Background(), config.Auth{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSFile: file,
+		Leeway:   time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, keyID string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// tamper changes the subject of a signed token, keeping its signature.
+func tamper(t *testing.T, token string) string {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload = []byte(strings.Replace(string(payload), "auth0|123", "auth0|999", 1))
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,297 @@
+// Package config holds the settings of the service. They come from built-in
+// defaults, an optional YAML or TOML file, environment variables and command
+// line flags, each overriding the one before.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SSL modes libpq and pgx accept for the database connection.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Config is every setting of the service, grouped the way the file nests them.
+type Config struct {
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+	Auth     Auth     `yaml:"auth" toml:"auth"`
+	Events   Events   `yaml:"events" toml:"events"`
+	Features Features `yaml:"features" toml:"features"`
+}
+
+// Server configures the HTTP listener.
+type Server struct {
+	Port         int           `yaml:"port" toml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	TLS          TLS           `yaml:"tls" toml:"tls"`
+
+	// ShutdownDelay keeps serving with readiness failing once shutdown
+	// starts, so load balancers stop sending traffic before the listener
+	// closes. ShutdownTimeout bounds the whole shutdown, draining included.
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// TLS serves HTTPS when both files are set.
+type TLS struct {
+	CertFile string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `yaml:"key_file" toml:"key_file"`
+}
+
+// Enabled reports whether the server should serve HTTPS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Database configures the PostgreSQL connection and its pool.
+type Database struct {
+	Host        string `yaml:"host" toml:"host"`
+	Port        int    `yaml:"port" toml:"port"`
+	User        string `yaml:"user" toml:"user"`
+	Password    string `yaml:"password" toml:"password"`
+	Name        string `yaml:"name" toml:"name"`
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"`
+
+	// Zero leaves the timeout to the driver or the server
+	ConnectTimeout   time.Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+
+	Pool Pool `yaml:"pool" toml:"pool"`
+}
+
+// Pool sizes the database/sql connection pool.
+type Pool struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+}
+
+// Auth configures bearer token authentication. Tokens are JWTs signed with
+// RS256 or ES256 by Issuer, checked against the keys of a JWKS served at
+// JWKSURL or, to work offline, read from JWKSFile.
+type Auth struct {
+	Issuer   string `yaml:"issuer" toml:"issuer"`
+	Audience string `yaml:"audience" toml:"audience"`
+	JWKSURL  string `yaml:"jwks_url" toml:"jwks_url"`
+	JWKSFile string `yaml:"jwks_file" toml:"jwks_file"`
+
+	// JWKSRefresh is how often keys are fetched again from JWKSURL
+	JWKSRefresh time.Duration `yaml:"jwks_refresh" toml:"jwks_refresh"`
+	// Leeway allows for clock skew when checking exp, nbf and iat
+	Leeway time.Duration `yaml:"leeway" toml:"leeway"`
+
+	// PublicRoutes are the route patterns, as registered, that need no token
+	PublicRoutes []string `yaml:"public_routes" toml:"public_routes"`
+	// AdminSubjects hold every permission whatever roles they are assigned,
+	// so the first roles can be handed out before anyone has one
+	AdminSubjects []string `yaml:"admin_subjects" toml:"admin_subjects"`
+}
+
+// Publishers the outbox relay can hand events to.
+var publishers = []string{"memory", "nats"}
+
+// Events configures the relay that publishes domain events from the outbox.
+// The memory publisher keeps events in the process, for development; nats
+// publishes to a NATS JetStream server at NATSURL, on Subject followed by
+// the aggregate and its ID, e.g. events.product.<id>.
+type Events struct {
+	Publisher string `yaml:"publisher" toml:"publisher"`
+	NATSURL   string `yaml:"nats_url" toml:"nats_url"`
+	Subject   string `yaml:"subject" toml:"subject"`
+
+	// PollInterval is how long the relay waits when the outbox is empty
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval"`
+	// BatchSize is the most events relayed in one transaction
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+	// PublishTimeout bounds each publish, acknowledgement included
+	PublishTimeout time.Duration `yaml:"publish_timeout" toml:"publish_timeout"`
+}
+
+// Features switches optional behaviour on and off.
+type Features struct {
+	// Auth requires a bearer token on every route but the public ones
+	Auth bool `yaml:"auth" toml:"auth"`
+	// Swagger serves the API documentation under /swagger
+	Swagger bool `yaml:"swagger" toml:"swagger"`
+	// AutoMigrate applies pending migrations when the service starts
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+	// LegacyRoutes keeps the deprecated PUT and DELETE routes that take the
+	// ID from the body or the query string
+	LegacyRoutes bool `yaml:"legacy_routes" toml:"legacy_routes"`
+	// Events relays domain events from the outbox to the publisher. Events
+	// are written to the outbox either way and wait there while it is off
+	Events bool `yaml:"events" toml:"events"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:         8080,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 60 * time.Second,
+			IdleTimeout:  2 * time.Minute,
+
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: Database{
+			Host:    "localhost",
+			Port:    5432,
+			SSLMode: "disable",
+			Pool: Pool{
+				MaxOpenConns:    1000,
+				MaxIdleConns:    10,
+				ConnMaxLifetime: 5 * time.Minute,
+			},
+		},
+		Auth: Auth{
+			JWKSRefresh:  time.Hour,
+			Leeway:       30 * time.Second,
+			PublicRoutes: []string{"/liveness", "/readiness", "/swagger/*"},
+		},
+		Events: Events{
+			Publisher:      "memory",
+			Subject:        "events",
+			PollInterval:   time.Second,
+			BatchSize:      100,
+			PublishTimeout: 5 * time.Second,
+		},
+		Features: Features{
+			Auth:         true,
+			Swagger:      true,
+			AutoMigrate:  true,
+			LegacyRoutes: true,
+		},
+	}
+}
+
+// Validate reports every setting that cannot work, joined into one error.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(setting string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s %s", setting, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		invalid("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	timeouts := []struct {
+		setting string
+		value   time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_delay", c.Server.ShutdownDelay},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"database.connect_timeout", c.Database.ConnectTimeout},
+		{"database.statement_timeout", c.Database.StatementTimeout},
+		{"database.pool.conn_max_lifetime", c.Database.Pool.ConnMaxLifetime},
+		{"database.pool.conn_max_idle_time", c.Database.Pool.ConnMaxIdleTime},
+		{"auth.jwks_refresh", c.Auth.JWKSRefresh},
+		{"auth.leeway", c.Auth.Leeway},
+		{"events.poll_interval", c.Events.PollInterval},
+		{"events.publish_timeout", c.Events.PublishTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			invalid(timeout.setting, "cannot be negative")
+		}
+	}
+
+	if c.Server.ShutdownTimeout == 0 {
+		invalid("server.shutdown_timeout", "must be more than zero")
+	} else if c.Server.ShutdownDelay >= c.Server.ShutdownTimeout {
+		invalid("server.shutdown_delay", "must be shorter than shutdown_timeout (%s) to leave time to drain", c.Server.ShutdownTimeout)
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		invalid("server.tls", "needs both cert_file and key_file")
+	}
+	files := []struct{ setting, path string }{
+		{"server.tls.cert_file", tls.CertFile},
+		{"server.tls.key_file", tls.KeyFile},
+		{"database.sslrootcert", c.Database.SSLRootCert},
+		{"auth.jwks_file", c.Auth.JWKSFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			invalid(file.setting, "cannot be read: %s", err)
+		}
+	}
+
+	db := c.Database
+	if db.Host == "" {
+		invalid("database.host", "is required")
+	}
+	if db.Port < 1 || db.Port > 65535 {
+		invalid("database.port", "must be between 1 and 65535, got %d", db.Port)
+	}
+	if db.User == "" {
+		invalid("database.user", "is required")
+	}
+	if db.Name == "" {
+		invalid("database.name", "is required")
+	}
+	if !slices.Contains(sslModes, db.SSLMode) {
+		invalid("database.sslmode", "must be one of %v, got %q", sslModes, db.SSLMode)
+	}
+	if db.Pool.MaxOpenConns < 0 {
+		invalid("database.pool.max_open_conns", "cannot be negative")
+	}
+	if db.Pool.MaxIdleConns < 0 {
+		invalid("database.pool.max_idle_conns", "cannot be negative")
+	}
+	if db.Pool.MaxOpenConns > 0 && db.Pool.MaxIdleConns > db.Pool.MaxOpenConns {
+		invalid("database.pool.max_idle_conns", "cannot exceed max_open_conns (%d)", db.Pool.MaxOpenConns)
+	}
+
+	if c.Features.Auth {
+		if c.Auth.Issuer == "" {
+			invalid("auth.issuer", "is required while the auth feature is on")
+		}
+		if (c.Auth.JWKSURL == "") == (c.Auth.JWKSFile == "") {
+			invalid("auth", "needs exactly one of jwks_url and jwks_file while the auth feature is on")
+		}
+		if c.Auth.JWKSURL != "" && !strings.HasPrefix(c.Auth.JWKSURL, "https://") && !strings.HasPrefix(c.Auth.JWKSURL, "http://") {
+			invalid("auth.jwks_url", "must be an http or https URL")
+		}
+	}
+
+	if c.Features.Events {
+		events := c.Events
+		if !slices.Contains(publishers, events.Publisher) {
+			invalid("events.publisher", "must be one of %v, got %q", publishers, events.Publisher)
+		}
+		if events.Publisher == "nats" && !strings.HasPrefix(events.NATSURL, "nats://") {
+			invalid("events.nats_url", "must be a nats:// URL while the publisher is nats")
+		}
+		if events.Subject == "" {
+			invalid("events.subject", "is required while the events feature is on")
+		}
+		if events.PollInterval == 0 {
+			invalid("events.poll_interval", "must be more than zero")
+		}
+		if events.BatchSize < 1 {
+			invalid("events.batch_size", "must be at least 1, got %d", events.BatchSize)
+		}
+		if events.PublishTimeout == 0 {
+			invalid("events.publish_timeout", "must be more than zero")
+		}
+	}
+
+	return errors.Join(errs...)
+}
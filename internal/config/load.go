@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// setting is one value that environment variables and flags can override.
+type setting struct {
+	Env   string
+	Flag  string
+	Usage string
+	Value value
+}
+
+// value parses a setting from text onto the field it points at.
+type value interface {
+	Set(text string) error
+	String() string
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(text string) error { *v.p = text; return nil }
+func (v stringValue) String() string        { return *v.p }
+
+// Numbers, booleans and durations left empty, as in DEFAULTPORT="", keep
+// their value as if they were not set at all.
+type intValue struct{ p *int }
+
+func (v intValue) Set(text string) error {
+	if text == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("%q is not a whole number", text)
+	}
+	*v.p = n
+	return nil
+}
+func (v intValue) String() string { return strconv.Itoa(*v.p) }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(text string) error {
+	if text == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(text)
+	if err != nil {
+		return fmt.Errorf("%q is not true or false", text)
+	}
+	*v.p = b
+	return nil
+}
+func (v boolValue) String() string { return strconv.FormatBool(*v.p) }
+
+type listValue struct{ p *[]string }
+
+// Set takes a comma-separated list; an empty string clears it.
+func (v listValue) Set(text string) error {
+	*v.p = nil
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v.p = append(*v.p, item)
+		}
+	}
+	return nil
+}
+func (v listValue) String() string { return strings.Join(*v.p, ",") }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(text string) error {
+	if text == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 30s or 5m", text)
+	}
+	*v.p = d
+	return nil
+}
+func (v durationValue) String() string { return v.p.String() }
+
+// settings lists what can be overridden on c. DEFAULTPORT and the DB_*
+// variables keep the names the service has always read.
+func (c *Config) settings() []setting {
+	return []setting{
+		{"DEFAULTPORT", "port", "HTTP port to listen on", intValue{&c.Server.Port}},
+		{"SERVER_READ_TIMEOUT", "read-timeout", "longest time to read a request", durationValue{&c.Server.ReadTimeout}},
+		{"SERVER_WRITE_TIMEOUT", "write-timeout", "longest time to write a response", durationValue{&c.Server.WriteTimeout}},
+		{"SERVER_IDLE_TIMEOUT", "idle-timeout", "how long an idle keep-alive connection stays open", durationValue{&c.Server.IdleTimeout}},
+		{"SERVER_SHUTDOWN_DELAY", "shutdown-delay", "how long to keep serving with readiness failing before draining", durationValue{&c.Server.ShutdownDelay}},
+		{"SERVER_SHUTDOWN_TIMEOUT", "shutdown-timeout", "longest time to wait for requests to drain on shutdown", durationValue{&c.Server.ShutdownTimeout}},
+		{"TLS_CERT_FILE", "tls-cert-file", "certificate to serve HTTPS with", stringValue{&c.Server.TLS.CertFile}},
+		{"TLS_KEY_FILE", "tls-key-file", "private key of the certificate", stringValue{&c.Server.TLS.KeyFile}},
+
+		{"DB_HOST", "db-host", "database host", stringValue{&c.Database.Host}},
+		{"DB_PORT", "db-port", "database port", intValue{&c.Database.Port}},
+		{"DB_USER", "db-user", "database user", stringValue{&c.Database.User}},
+		{"DB_PASSWORD", "db-password", "database password", stringValue{&c.Database.Password}},
+		{"DB_NAME", "db-name", "database name", stringValue{&c.Database.Name}},
+		{"DB_SSLMODE", "db-sslmode", "database SSL mode", stringValue{&c.Database.SSLMode}},
+		{"DB_SSLROOTCERT", "db-sslrootcert", "CA certificate to verify the database with", stringValue{&c.Database.SSLRootCert}},
+		{"DB_CONNECT_TIMEOUT", "db-connect-timeout", "longest time to open a connection, 0 for none", durationValue{&c.Database.ConnectTimeout}},
+		{"DB_STATEMENT_TIMEOUT", "db-statement-timeout", "longest time a statement may run, 0 for none", durationValue{&c.Database.StatementTimeout}},
+		{"DB_MAX_OPEN_CONNS", "db-max-open-conns", "most open connections, 0 for no limit", intValue{&c.Database.Pool.MaxOpenConns}},
+		{"DB_MAX_IDLE_CONNS", "db-max-idle-conns", "most idle connections kept", intValue{&c.Database.Pool.MaxIdleConns}},
+		{"DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "how long a connection is reused, 0 for ever", durationValue{&c.Database.Pool.ConnMaxLifetime}},
+		{"DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "how long a connection may sit idle, 0 for ever", durationValue{&c.Database.Pool.ConnMaxIdleTime}},
+
+		{"AUTH_ISSUER", "auth-issuer", "issuer bearer tokens must come from", stringValue{&c.Auth.Issuer}},
+		{"AUTH_AUDIENCE", "auth-audience", "audience bearer tokens must name, empty for any", stringValue{&c.Auth.Audience}},
+		{"AUTH_JWKS_URL", "auth-jwks-url", "URL of the issuer's JSON Web Key Set", stringValue{&c.Auth.JWKSURL}},
+		{"AUTH_JWKS_FILE", "auth-jwks-file", "JSON Web Key Set file to use instead of a URL", stringValue{&c.Auth.JWKSFile}},
+		{"AUTH_JWKS_REFRESH", "auth-jwks-refresh", "how often to fetch the JSON Web Key Set again", durationValue{&c.Auth.JWKSRefresh}},
+		{"AUTH_LEEWAY", "auth-leeway", "clock skew allowed when checking token times", durationValue{&c.Auth.Leeway}},
+		{"AUTH_PUBLIC_ROUTES", "auth-public-routes", "comma-separated routes that need no token", listValue{&c.Auth.PublicRoutes}},
+		{"AUTH_ADMIN_SUBJECTS", "auth-admin-subjects", "comma-separated token subjects that hold every permission", listValue{&c.Auth.AdminSubjects}},
+
+		{"EVENTS_PUBLISHER", "events-publisher", "where to publish domain events: memory or nats", stringValue{&c.Events.Publisher}},
+		{"EVENTS_NATS_URL", "events-nats-url", "NATS server to publish domain events to", stringValue{&c.Events.NATSURL}},
+		{"EVENTS_SUBJECT", "events-subject", "subject prefix domain events are published on", stringValue{&c.Events.Subject}},
+		{"EVENTS_POLL_INTERVAL", "events-poll-interval", "how often to look for new events in the outbox", durationValue{&c.Events.PollInterval}},
+		{"EVENTS_BATCH_SIZE", "events-batch-size", "most events relayed in one transaction", intValue{&c.Events.BatchSize}},
+		{"EVENTS_PUBLISH_TIMEOUT", "events-publish-timeout", "longest time to wait for an event to be acknowledged", durationValue{&c.Events.PublishTimeout}},
+
+		{"FEATURE_AUTH", "feature-auth", "require a bearer token on all but the public routes", boolValue{&c.Features.Auth}},
+		{"FEATURE_SWAGGER", "feature-swagger", "serve the API documentation under /swagger", boolValue{&c.Features.Swagger}},
+		{"FEATURE_AUTO_MIGRATE", "feature-auto-migrate", "apply pending migrations on startup", boolValue{&c.Features.AutoMigrate}},
+		{"FEATURE_LEGACY_ROUTES", "feature-legacy-routes", "keep the deprecated collection PUT and DELETE routes", boolValue{&c.Features.LegacyRoutes}},
+		{"FEATURE_EVENTS", "feature-events", "relay domain events from the outbox to the publisher", boolValue{&c.Features.Events}},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, the file named by -config or CONFIG_FILE, environment variables
+// and the flags in args, then validates it. Flags are registered on fs, so
+// whatever follows them stays available through fs.Args.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	c := Default()
+	settings := c.settings()
+
+	file := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML file to read settings from (env CONFIG_FILE)")
+	for _, s := range settings {
+		fs.String(s.Flag, s.Value.String(), s.Usage+" (env "+s.Env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *file != "" {
+		if err := c.readFile(*file); err != nil {
+			return nil, err
+		}
+	}
+
+	flags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	for _, s := range settings {
+		if text, ok := os.LookupEnv(s.Env); ok {
+			if err := s.Value.Set(text); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.Env, err)
+			}
+		}
+		if text, ok := flags[s.Flag]; ok {
+			if err := s.Value.Set(text); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.Flag, err)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return c, nil
+}
+
+// readFile overlays the settings in a .yaml, .yml or .toml file onto c.
+// Keys the file does not know are rejected so typos do not pass silently.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config file %s: unknown setting %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("config file %s: must end in .yaml, .yml or .toml", path)
+	}
+	return nil
+}
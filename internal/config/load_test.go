@@ -0,0 +1,147 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// required holds the settings that have no default, so each case only sets
+// what it is about. Files without features of their own get noAuth, which
+// spares them an issuer and key set.
+const (
+	required = `
+database:
+  user: wisdom
+  name: wisdom
+`
+	noAuth = `
+features:
+  auth: false
+`
+)
+
+// Settings come from the defaults, then the config file, then environment
+// variables, then flags, each overriding the one before. Numbers, booleans
+// and durations set to an empty string count as unset.
+func TestLoad(t *testing.T) {
+	type want struct {
+		port        int
+		readTimeout time.Duration
+		swagger     bool
+		batchSize   int
+	}
+	defaults := want{port: 8080, readTimeout: 30 * time.Second, swagger: true, batchSize: 100}
+
+	fromFile := `
+server:
+  port: 9000
+  read_timeout: 10s
+features:
+  auth: false
+  swagger: false
+`
+
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		want    want
+		invalid string
+	}{
+		{name: "defaults", want: defaults},
+		{name: "file", file: fromFile, want: want{port: 9000, readTimeout: 10 * time.Second, swagger: false, batchSize: 100}},
+		{
+			name: "env over file",
+			file: fromFile,
+			env:  map[string]string{"DEFAULTPORT": "9100", "SERVER_READ_TIMEOUT": "20s", "FEATURE_SWAGGER": "true"},
+			want: want{port: 9100, readTimeout: 20 * time.Second, swagger: true, batchSize: 100},
+		},
+		{
+			name: "flags over env",
+			file: fromFile,
+			env:  map[string]string{"DEFAULTPORT": "9100", "SERVER_READ_TIMEOUT": "20s"},
+			args: []string{"-port", "9200", "-read-timeout=40s", "-feature-swagger=true"},
+			want: want{port: 9200, readTimeout: 40 * time.Second, swagger: true, batchSize: 100},
+		},
+		{
+			name: "flags over defaults",
+			args: []string{"-events-batch-size", "5"},
+			want: want{port: 8080, readTimeout: 30 * time.Second, swagger: true, batchSize: 5},
+		},
+		{
+			name: "empty env keeps defaults",
+			env:  map[string]string{"DEFAULTPORT": "", "SERVER_READ_TIMEOUT": "", "FEATURE_SWAGGER": "", "EVENTS_BATCH_SIZE": ""},
+			want: defaults,
+		},
+		{
+			name: "empty env keeps file",
+			file: fromFile,
+			env:  map[string]string{"DEFAULTPORT": "", "SERVER_READ_TIMEOUT": "", "FEATURE_SWAGGER": ""},
+			want: want{port: 9000, readTimeout: 10 * time.Second, swagger: false, batchSize: 100},
+		},
+		{name: "bad number in env", env: map[string]string{"DEFAULTPORT": "eighty"}, invalid: `DEFAULTPORT: "eighty" is not a whole number`},
+		{name: "bad duration in env", env: map[string]string{"SERVER_READ_TIMEOUT": "30"}, invalid: "SERVER_READ_TIMEOUT"},
+		{name: "bad boolean in env", env: map[string]string{"FEATURE_SWAGGER": "maybe"}, invalid: "FEATURE_SWAGGER"},
+		{name: "bad flag", args: []string{"-port", "eighty"}, invalid: "-port"},
+		{name: "unknown key in file", file: "server:\n  prot: 9000\n", invalid: "prot"},
+		{name: "invalid result", env: map[string]string{"DEFAULTPORT": "70000"}, invalid: "server.port must be between 1 and 65535"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+
+			settings := test.file
+			if !strings.Contains(settings, "features:") {
+				settings += noAuth
+			}
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(required+settings), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("CONFIG_FILE", file)
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			c, err := Load(fs, test.args)
+
+			if test.invalid != "" {
+				if err == nil || !strings.Contains(err.Error(), test.invalid) {
+					t.Fatalf("Load = %v, want an error mentioning %s", err, test.invalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load = %v", err)
+			}
+
+			got := want{port: c.Server.Port, readTimeout: c.Server.ReadTimeout, swagger: c.Features.Swagger, batchSize: c.Events.BatchSize}
+			if got != test.want {
+				t.Errorf("Load = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// clearEnv unsets every variable Load reads until t ends, so settings in the
+// environment running the tests do not leak in.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := []string{"CONFIG_FILE"}
+	for _, s := range Default().settings() {
+		names = append(names, s.Env)
+	}
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
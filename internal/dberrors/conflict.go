@@ -1,7 +1,12 @@
 package dberrors
 
-type ConflictError struct{}
+type ConflictError struct {
+	Message string
+}
 
 func (e *ConflictError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
 	return "attempted to create a record with an existing key"
 }
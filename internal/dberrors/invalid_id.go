@@ -0,0 +1,24 @@
+package dberrors
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type InvalidIDError struct {
+	Value string
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("%q is not a valid id", e.Value)
+}
+
+// ParseID parses id as a UUID, reporting failures as an InvalidIDError.
+func ParseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, &InvalidIDError{Value: id}
+	}
+	return parsed, nil
+}
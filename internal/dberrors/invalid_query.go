@@ -0,0 +1,14 @@
+package dberrors
+
+import "fmt"
+
+// InvalidQueryError is returned when a list query parameter such as filter,
+// sort or cursor cannot be understood.
+type InvalidQueryError struct {
+	Parameter string
+	Message   string
+}
+
+func (e *InvalidQueryError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Parameter, e.Message)
+}
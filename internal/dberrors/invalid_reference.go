@@ -0,0 +1,18 @@
+package dberrors
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// InvalidReferenceError is returned when a record being written points at
+// another record that does not exist.
+type InvalidReferenceError struct {
+	Entity string
+	ID     uuid.UUID
+}
+
+func (e *InvalidReferenceError) Error() string {
+	return fmt.Sprintf("referenced %s with id %s does not exist", e.Entity, e.ID)
+}
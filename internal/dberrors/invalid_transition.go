@@ -0,0 +1,13 @@
+package dberrors
+
+import "fmt"
+
+type InvalidTransitionError struct {
+	Entity string
+	From   string
+	To     string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s cannot move from %s to %s", e.Entity, e.From, e.To)
+}
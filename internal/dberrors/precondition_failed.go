@@ -0,0 +1,19 @@
+package dberrors
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PreconditionFailedError is returned when a conditional write names a
+// version of the record that is no longer current. Version is the current one.
+type PreconditionFailedError struct {
+	Entity  string
+	ID      uuid.UUID
+	Version int
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("%s %s has changed and is now at version %d", e.Entity, e.ID, e.Version)
+}
@@ -0,0 +1,19 @@
+package dberrors
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ReferencedError is returned when a record cannot be removed because other
+// records still point at it.
+type ReferencedError struct {
+	Entity     string
+	ID         uuid.UUID
+	Dependents string
+}
+
+func (e *ReferencedError) Error() string {
+	return fmt.Sprintf("unable to delete %s with id %s: it is still referenced by %s", e.Entity, e.ID, e.Dependents)
+}
@@ -0,0 +1,9 @@
+package dberrors
+
+// RolledBackError is reported for a write in an atomic batch that succeeded
+// on its own but was undone because another write in the batch failed.
+type RolledBackError struct{}
+
+func (e *RolledBackError) Error() string {
+	return "rolled back because another write in the batch failed"
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// MemoryPublisher keeps published events in the process and passes them to
+// its subscribers, for development and for running without a broker.
+type MemoryPublisher struct {
+	mu          sync.Mutex
+	events      []models.Event
+	subscribers []func(event models.Event)
+}
+
+// NewMemoryPublisher returns a publisher holding no events.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Subscribe calls handle with every event published from now on, in order
+// for events published one at a time.
+func (p *MemoryPublisher) Subscribe(handle func(event models.Event)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, handle)
+}
+
+// Events returns the events published so far, oldest first.
+func (p *MemoryPublisher) Events() []models.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]models.Event(nil), p.events...)
+}
+
+// Publish records event and then calls the subscribers, outside the lock so
+// they may use the publisher themselves.
+func (p *MemoryPublisher) Publish(ctx context.Context, event *models.Event) error {
+	p.mu.Lock()
+	p.events = append(p.events, *event)
+	subscribers := slices.Clone(p.subscribers)
+	p.mu.Unlock()
+
+	for _, handle := range subscribers {
+		handle(*event)
+	}
+	return nil
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
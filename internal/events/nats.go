@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Headers set on every message, so consumers can route events without
+// decoding them.
+const (
+	headerEventType     = "Event-Type"
+	headerAggregateType = "Aggregate-Type"
+	headerAggregateID   = "Aggregate-Id"
+)
+
+// unavailable are the errors that mean no event can be published for now,
+// as the server or the stream cannot be reached.
+var unavailable = []error{
+	nats.ErrConnectionClosed,
+	nats.ErrConnectionDraining,
+	nats.ErrConnectionReconnecting,
+	nats.ErrDisconnected,
+	nats.ErrTimeout,
+	nats.ErrNoResponders,
+	jetstream.ErrNoStreamResponse,
+}
+
+// NATSPublisher publishes events to a NATS JetStream stream, each on the
+// subject <subject>.<aggregate type>.<aggregate ID>. The stream must exist
+// and take those subjects. Events carry their EventID as the message ID,
+// so JetStream drops repeats within its duplicate window.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	stream  jetstream.JetStream
+	subject string
+}
+
+// NewNATSPublisher connects to the NATS server at url. The client
+// reconnects by itself when the connection drops.
+func NewNATSPublisher(url string, subject string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("go-microservices"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("NATS: %w", err)
+	}
+	stream, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("NATS JetStream: %w", err)
+	}
+	return &NATSPublisher{conn: conn, stream: stream, subject: subject}, nil
+}
+
+// Publish returns once the stream has stored event.
+func (p *NATSPublisher) Publish(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.subject + "." + event.AggregateType + "." + event.AggregateID)
+	msg.Data = data
+	msg.Header.Set(headerEventType, event.Type)
+	msg.Header.Set(headerAggregateType, event.AggregateType)
+	msg.Header.Set(headerAggregateID, event.AggregateID)
+
+	if _, err := p.stream.PublishMsg(ctx, msg, jetstream.WithMsgID(event.EventID.String())); err != nil {
+		for _, target := range unavailable {
+			if errors.Is(err, target) {
+				return fmt.Errorf("publish %s %s: %w: %w", event.Type, event.EventID, models.ErrPublisherUnavailable, err)
+			}
+		}
+		return fmt.Errorf("publish %s %s: %w", event.Type, event.EventID, err)
+	}
+	return nil
+}
+
+// Close sends whatever is buffered and closes the connection.
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,33 @@
+// Package events relays the domain events the service raises, which are
+// written to the outbox in the transaction of the change that raised them,
+// to a Publisher that hands them on to other services.
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/johnifegwu/go-microservices/internal/config"
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// Publisher hands events on to their consumers. Publish returns once the
+// event is safely accepted; an event whose Publish fails is retried, so
+// an event may arrive more than once and consumers should drop repeats by
+// its EventID.
+type Publisher interface {
+	Publish(ctx context.Context, event *models.Event) error
+	Close() error
+}
+
+// NewPublisher returns the publisher cfg names.
+func NewPublisher(cfg config.Events) (Publisher, error) {
+	switch cfg.Publisher {
+	case "memory":
+		return NewMemoryPublisher(), nil
+	case "nats":
+		return NewNATSPublisher(cfg.NATSURL, cfg.Subject)
+	default:
+		return nil, fmt.Errorf("unknown events publisher %q", cfg.Publisher)
+	}
+}
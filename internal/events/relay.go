@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/johnifegwu/go-microservices/internal/config"
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// maxBackoff caps how long the relay waits between attempts while events
+// keep failing.
+const maxBackoff = time.Minute
+
+// Outbox is where the relay finds events to publish.
+type Outbox interface {
+	PublishEvents(ctx context.Context, limit int, publish func(ctx context.Context, event *models.Event) error) (int, bool, error)
+}
+
+// Relay moves events from the outbox to a publisher. Events are published
+// at least once, and those of one aggregate in the order they were raised.
+type Relay struct {
+	outbox    Outbox
+	publisher Publisher
+	interval  time.Duration
+	batch     int
+	timeout   time.Duration
+
+	// unavailable is set when a publish in the current batch found the
+	// publisher unavailable
+	unavailable bool
+}
+
+// NewRelay returns a relay from outbox to publisher, paced by cfg.
+func NewRelay(outbox Outbox, publisher Publisher, cfg config.Events) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		interval:  cfg.PollInterval,
+		batch:     cfg.BatchSize,
+		timeout:   cfg.PublishTimeout,
+	}
+}
+
+// Run relays events until ctx is done. A full batch is followed straight
+// away by the next, so a backlog drains without waiting for the interval.
+// A batch with failed events is retried after the interval, and while the
+// outbox or the publisher is unavailable the relay waits twice as long after
+// each attempt, up to maxBackoff, so an outage is not retried in a tight
+// loop.
+func (r *Relay) Run(ctx context.Context) {
+	wait := r.interval
+	for {
+		r.unavailable = false
+		read, failed, err := r.outbox.PublishEvents(ctx, r.batch, r.publish)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("failed to relay events: %s", err)
+		}
+
+		switch {
+		case err != nil || r.unavailable:
+			wait = min(wait*2, max(maxBackoff, r.interval))
+		case failed:
+			wait = r.interval
+		case read == r.batch:
+			wait = r.interval
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		default:
+			wait = r.interval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// publish hands one event to the publisher, giving up after the timeout. A
+// publisher too slow to answer is taken to be unavailable.
+func (r *Relay) publish(ctx context.Context, event *models.Event) error {
+	publishing, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.publisher.Publish(publishing, event)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, models.ErrPublisherUnavailable) {
+		err = fmt.Errorf("%w: %w", models.ErrPublisherUnavailable, err)
+	}
+	if errors.Is(err, models.ErrPublisherUnavailable) {
+		r.unavailable = true
+	}
+	log.Printf("failed to publish event %s: %s", event.EventID, err)
+	return err
+}
@@ -0,0 +1,291 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/johnifegwu/go-microservices/internal/auth"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditedTables names the entity each audited table holds, as the audit
+// endpoint takes it.
+var auditedTables = map[string]string{
+	"wisdom.customers":            "customer",
+	"wisdom.pets":                 "pet",
+	"wisdom.products":             "product",
+	"wisdom.stock_movements":      "stock_movement",
+	"wisdom.services":             "service",
+	"wisdom.vendors":              "vendor",
+	"wisdom.purchase_orders":      "purchase_order",
+	"wisdom.purchase_order_lines": "purchase_order_line",
+	"wisdom.orders":               "order",
+	"wisdom.order_lines":          "order_line",
+	"wisdom.appointments":         "appointment",
+	"wisdom.roles":                "role",
+	"wisdom.role_assignments":     "role_assignment",
+	"wisdom.api_keys":             "api_key",
+}
+
+// auditIgnored are columns of a table that are left out of its entries, so
+// that changing only them records nothing.
+var auditIgnored = map[string][]string{
+	"wisdom.api_keys": {"last_used_at"},
+}
+
+// auditBefore is where a statement keeps the rows it is about to change.
+const auditBefore = "audit:before"
+
+// registerAudit makes every create, update and delete of an audited table
+// record an entry per row in wisdom.audit_log, and add the domain events the
+// change raises to wisdom.outbox. Entries and events are written by GORM
+// callbacks inside the transaction of the change, so a change that rolls
+// back leaves no entry and an entry that cannot be written rolls the change
+// back.
+func registerAudit(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:record_create", recordAudit(models.AuditCreate)); err != nil {
+		return err
+	}
+
+	if err := callbacks.Update().Before("gorm:update").Register("audit:capture_update", captureAudit); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:record_update", recordAudit(models.AuditUpdate)); err != nil {
+		return err
+	}
+
+	if err := callbacks.Delete().Before("gorm:delete").Register("audit:capture_delete", captureAudit); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:record_delete", recordAudit(models.AuditDelete))
+}
+
+// auditedEntity is the entity db's statement changes, if it is audited.
+func auditedEntity(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return "", false
+	}
+	entity, ok := auditedTables[db.Statement.Schema.Table]
+	return entity, ok
+}
+
+// captureAudit loads the rows an update or delete is about to change, while
+// they still hold their old values.
+func captureAudit(db *gorm.DB) {
+	if _, ok := auditedEntity(db); !ok {
+		return
+	}
+
+	stmt := db.Statement
+	query := auditQuery(db)
+	conditions := false
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		query = query.Clauses(where)
+		conditions = true
+	}
+	if keys := primaryKeys(stmt, stmt.ReflectValue); keys != nil {
+		query = query.Where(keys)
+		conditions = true
+	}
+	// GORM refuses to change every row, so there is nothing to capture
+	if !conditions {
+		return
+	}
+
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	db.InstanceSet(auditBefore, rows.Elem())
+}
+
+// recordAudit writes an entry for every row the statement changed, holding
+// the fields whose values differ between before and after.
+func recordAudit(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		entity, ok := auditedEntity(db)
+		if !ok {
+			return
+		}
+
+		stmt := db.Statement
+		before := reflect.ValueOf([]interface{}{})
+		if captured, ok := db.InstanceGet(auditBefore); ok {
+			before = captured.(reflect.Value)
+		}
+
+		// Rows are read back so values the database filled in are recorded
+		changed := stmt.ReflectValue
+		if action != models.AuditCreate {
+			changed = before
+		}
+		keys := primaryKeys(stmt, changed)
+		if keys == nil {
+			return
+		}
+		after := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+		if err := auditQuery(db).Where(keys).Find(after.Interface()).Error; err != nil {
+			db.AddError(fmt.Errorf("audit: %w", err))
+			return
+		}
+
+		oldRows := auditRows(stmt, before)
+		newRows := auditRows(stmt, after.Elem())
+		ids := make([]string, 0, len(newRows))
+		for id := range newRows {
+			ids = append(ids, id)
+		}
+		for id := range oldRows {
+			if _, ok := newRows[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+
+		actor := ""
+		if principal, ok := auth.PrincipalFrom(stmt.Context); ok {
+			actor = principal.Subject
+		}
+
+		var entries []models.AuditEntry
+		var events []models.Event
+		for _, id := range ids {
+			changes := diffAudit(stmt, oldRows[id], newRows[id])
+			if len(changes) == 0 {
+				continue
+			}
+			raw, err := json.Marshal(changes)
+			if err != nil {
+				db.AddError(fmt.Errorf("audit: %w", err))
+				return
+			}
+			entries = append(entries, models.AuditEntry{
+				OccurredAt: db.NowFunc(),
+				Actor:      actor,
+				Action:     action,
+				Entity:     entity,
+				EntityID:   id,
+				Changes:    raw,
+			})
+
+			raised, err := domainEvents(entity, action, changes, oldRows[id], newRows[id])
+			if err != nil {
+				db.AddError(fmt.Errorf("outbox: %w", err))
+				return
+			}
+			for _, event := range raised {
+				event.AggregateID = id
+				event.Actor = actor
+				event.OccurredAt = db.NowFunc()
+				events = append(events, event)
+			}
+		}
+		if len(entries) == 0 {
+			return
+		}
+
+		if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+			db.AddError(fmt.Errorf("audit: %w", err))
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+		// Events are raised in the same transaction, so they are published
+		// exactly when the change commits
+		if err := db.Session(&gorm.Session{NewDB: true}).Create(&events).Error; err != nil {
+			db.AddError(fmt.Errorf("outbox: %w", err))
+		}
+	}
+}
+
+// auditQuery starts a query in the statement's transaction. Its table comes
+// from the model it finds, and it sees soft-deleted rows, so deleting and
+// restoring are recorded too.
+func auditQuery(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Unscoped()
+}
+
+// primaryKeys is a condition matching the records value holds by their
+// primary keys, or nil when it holds none with a key set.
+func primaryKeys(stmt *gorm.Statement, value reflect.Value) clause.Expression {
+	if !value.IsValid() {
+		return nil
+	}
+	_, keys := schema.GetIdentityFieldValuesMap(stmt.Context, value, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, keys)
+	if len(values) == 0 {
+		return nil
+	}
+	return clause.IN{Column: column, Values: values}
+}
+
+// auditRows indexes the records in rows, a slice of models, by their ID as
+// entries record it: the primary key, or its columns joined with / when it
+// has several.
+func auditRows(stmt *gorm.Statement, rows reflect.Value) map[string]reflect.Value {
+	indexed := map[string]reflect.Value{}
+	for i := 0; i < rows.Len(); i++ {
+		row := reflect.Indirect(rows.Index(i))
+		if row.Kind() != reflect.Struct {
+			continue
+		}
+		var parts []string
+		for _, field := range stmt.Schema.PrimaryFields {
+			value, _ := field.ValueOf(stmt.Context, row)
+			parts = append(parts, fmt.Sprint(value))
+		}
+		indexed[strings.Join(parts, "/")] = row
+	}
+	return indexed
+}
+
+// diffAudit maps each field whose value differs between before and after,
+// either of which may be missing, to its old and new value. Fields the API
+// never shows, such as key hashes, are left out.
+func diffAudit(stmt *gorm.Statement, before, after reflect.Value) map[string]models.AuditChange {
+	ignored := map[string]bool{}
+	for _, column := range auditIgnored[stmt.Schema.Table] {
+		ignored[column] = true
+	}
+
+	changes := map[string]models.AuditChange{}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || ignored[field.DBName] || strings.HasPrefix(field.Tag.Get("json"), "-") {
+			continue
+		}
+
+		old := auditValue(stmt, field, before)
+		new := auditValue(stmt, field, after)
+		if string(old) == string(new) {
+			continue
+		}
+		changes[jsonName(field)] = models.AuditChange{Old: old, New: new}
+	}
+	return changes
+}
+
+// auditValue is the JSON of field in row, null when there is no row.
+func auditValue(stmt *gorm.Statement, field *schema.Field, row reflect.Value) json.RawMessage {
+	if !row.IsValid() {
+		return json.RawMessage("null")
+	}
+	value, _ := field.ValueOf(stmt.Context, row)
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return raw
+}
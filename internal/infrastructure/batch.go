@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxBatchSize caps the number of writes in one batch so a single request
+// cannot hold a transaction open indefinitely.
+const maxBatchSize = 1000
+
+// errRollback ends the transaction of an atomic batch that failed, or of a
+// dry run, once every write has been tried.
+var errRollback = errors.New("rollback")
+
+// batchWriter holds the single-record writes of an entity, each taking the
+// transaction to run on.
+type batchWriter[T any] struct {
+	Create func(tx *gorm.DB, item *T) (*T, error)
+	Update func(tx *gorm.DB, item *T) (*T, error)
+	Delete func(tx *gorm.DB, id string, version int) (int64, error)
+}
+
+// write is one write of a batch or import. Run performs it on a savepoint.
+type write[T any] struct {
+	Operation string
+	Index     int
+	ID        string
+	Run       func(tx *gorm.DB) (*T, error)
+}
+
+// runWrites applies writes in one transaction, each in its own savepoint so
+// a failing write only undoes itself. Every write is tried so all failures
+// are reported at once; in atomic mode any failure then rolls back the whole
+// transaction, as does a dry run.
+func runWrites[T any](db *gorm.DB, mode string, dryRun bool, writes []write[T]) ([]models.BatchResult[T], error) {
+	results := make([]models.BatchResult[T], len(writes))
+	failed := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i, w := range writes {
+			result := models.BatchResult[T]{Operation: w.Operation, Index: w.Index, ID: w.ID}
+			result.Err = tx.Transaction(func(savepoint *gorm.DB) error {
+				item, err := w.Run(savepoint)
+				result.Item = item
+				return err
+			})
+			if result.Err != nil {
+				result.Item = nil
+				failed = true
+			}
+			results[i] = result
+		}
+
+		if dryRun || (failed && mode == models.BatchAtomic) {
+			return errRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+
+	if failed && mode == models.BatchAtomic {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Item = nil
+				results[i].Err = &dberrors.RolledBackError{}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// validated runs write after checking item against the model's rules.
+func validated[T any](item *T, write func(tx *gorm.DB, item *T) (*T, error)) func(tx *gorm.DB) (*T, error) {
+	return func(tx *gorm.DB) (*T, error) {
+		if err := models.Validate(item); err != nil {
+			return nil, err
+		}
+		return write(tx, item)
+	}
+}
+
+// runBatch applies every write of batch in one transaction as runWrites
+// describes.
+func runBatch[T any](db *gorm.DB, batch *models.Batch[T], writer batchWriter[T]) ([]models.BatchResult[T], error) {
+	if err := models.Validate(batch); err != nil {
+		return nil, err
+	}
+	if batch.Mode == "" {
+		batch.Mode = models.BatchAtomic
+	}
+
+	size := len(batch.Create) + len(batch.Update) + len(batch.Delete)
+	if size == 0 {
+		return nil, &dberrors.ValidationError{Field: "batch", Message: "needs at least one create, update or delete"}
+	}
+	if size > maxBatchSize {
+		return nil, &dberrors.ValidationError{Field: "batch", Message: fmt.Sprintf("may hold at most %d writes", maxBatchSize)}
+	}
+
+	writes := make([]write[T], 0, size)
+	for i := range batch.Create {
+		writes = append(writes, write[T]{Operation: models.BatchCreate, Index: i, Run: validated(&batch.Create[i], writer.Create)})
+	}
+	for i := range batch.Update {
+		writes = append(writes, write[T]{Operation: models.BatchUpdate, Index: i, Run: validated(&batch.Update[i], writer.Update)})
+	}
+	for i, target := range batch.Delete {
+		target := target
+		writes = append(writes, write[T]{Operation: models.BatchDelete, Index: i, ID: target.ID, Run: func(tx *gorm.DB) (*T, error) {
+			rows, err := writer.Delete(tx, target.ID, target.Version)
+			if err == nil && rows < 1 {
+				err = &dberrors.ZeroRowsAffectedError{}
+			}
+			return nil, err
+		}})
+	}
+
+	return runWrites(db, batch.Mode, false, writes)
+}
+
+func (c Client) BatchProducts(ctx context.Context, batch *models.Batch[models.Product]) ([]models.BatchResult[models.Product], error) {
+	return runBatch(c.DB.WithContext(ctx), batch, productWriter)
+}
+
+func (c Client) BatchCustomers(ctx context.Context, batch *models.Batch[models.Customer]) ([]models.BatchResult[models.Customer], error) {
+	return runBatch(c.DB.WithContext(ctx), batch, customerWriter)
+}
+
+func (c Client) BatchServices(ctx context.Context, batch *models.Batch[models.Service]) ([]models.BatchResult[models.Service], error) {
+	return runBatch(c.DB.WithContext(ctx), batch, serviceWriter)
+}
+
+// BatchVendors deletes vendors without cascading, so a vendor that still has
+// products fails on its own.
+func (c Client) BatchVendors(ctx context.Context, batch *models.Batch[models.Vendor]) ([]models.BatchResult[models.Vendor], error) {
+	return runBatch(c.DB.WithContext(ctx), batch, vendorWriter)
+}
+
+var (
+	productWriter  = batchWriter[models.Product]{Create: addProduct, Update: updateProduct, Delete: deleteProduct}
+	customerWriter = batchWriter[models.Customer]{Create: addCustomer, Update: updateCustomer, Delete: deleteCustomer}
+	serviceWriter  = batchWriter[models.Service]{Create: addService, Update: updateService, Delete: deleteService}
+	vendorWriter   = batchWriter[models.Vendor]{
+		Create: addVendor,
+		Update: updateVendor,
+		Delete: func(tx *gorm.DB, id string, version int) (int64, error) {
+			return deleteVendor(tx, id, false, version)
+		},
+	}
+)
@@ -2,12 +2,14 @@ package database
 
 import (
 	"context"
-	"embed"
 	"fmt"
-	"log"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/config"
+	"github.com/johnifegwu/go-microservices/internal/infrastructure/migrations"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -15,55 +17,147 @@ import (
 
 type DatabaseClient interface {
 	Ready() bool
+	Close() error
 
-	SearchProducts(ctx context.Context, searchterm string, pageindex string, pagesize string) ([]models.Product, error)
-	GetAllProducts(ctx context.Context, pageIndex string, pageSize string) ([]models.Product, error)
+	SearchProducts(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Product]], error)
+	GetAllProducts(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Product], error)
 	GetProductById(ctx context.Context, productId string) (*models.Product, error)
-	GetAllProductsByVendor(ctx context.Context, vendorID string, pageIndex string, pageSize string) ([]models.Product, error)
-	GetAllCustomers(ctx context.Context, email, pageindex, pagesize string) ([]models.Customer, error)
+	GetAllProductsByVendor(ctx context.Context, vendorID string, page models.PageRequest) (*models.Page[models.Product], error)
+	GetAllCustomers(ctx context.Context, email string, page models.PageRequest, includeDeleted bool) (*models.Page[models.Customer], error)
 	AddProduct(ctx context.Context, product *models.Product) (*models.Product, error)
 	UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error)
-
-	DeleteProduct(ctx context.Context, productId string) (int64, error)
+	PatchProduct(ctx context.Context, productId string, version int, apply func(product *models.Product) (*models.Product, error)) (*models.Product, error)
+
+	DeleteProduct(ctx context.Context, productId string, version int) (int64, error)
+	BatchProducts(ctx context.Context, batch *models.Batch[models.Product]) ([]models.BatchResult[models.Product], error)
+	ExportProducts(ctx context.Context, filters []string, each func(product *models.Product) error) error
+	ImportProducts(ctx context.Context, rows []models.ImportRow[models.Product], options models.ImportOptions) ([]models.BatchResult[models.Product], error)
+	RestoreProduct(ctx context.Context, productId string) (*models.Product, error)
+	PurgeProduct(ctx context.Context, productId string) (int64, error)
+
+	GetProductStock(ctx context.Context, productId string) (*models.StockLevel, error)
+	SetReorderThreshold(ctx context.Context, productId string, threshold int, version int) (*models.StockLevel, error)
+	GetStockMovements(ctx context.Context, productId string, page models.PageRequest) (*models.Page[models.StockMovement], error)
+	RecordStockMovement(ctx context.Context, movement *models.StockMovement) (*models.StockLevel, error)
+	GetLowStockProductsByVendor(ctx context.Context, vendorID string, page models.PageRequest) (*models.Page[models.Product], error)
 	GetCustomerById(ctx context.Context, customerId string) (*models.Customer, error)
 	AddCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error)
 	UpdateCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error)
-	DeleteCustomer(ctx context.Context, customerId string) (int64, error)
-
-	GetAllServices(ctx context.Context, pageIndex string, pageSize string) ([]models.Service, error)
+	PatchCustomer(ctx context.Context, customerId string, version int, apply func(customer *models.Customer) (*models.Customer, error)) (*models.Customer, error)
+	DeleteCustomer(ctx context.Context, customerId string, version int) (int64, error)
+	BatchCustomers(ctx context.Context, batch *models.Batch[models.Customer]) ([]models.BatchResult[models.Customer], error)
+	ExportCustomers(ctx context.Context, filters []string, each func(customer *models.Customer) error) error
+	ImportCustomers(ctx context.Context, rows []models.ImportRow[models.Customer], options models.ImportOptions) ([]models.BatchResult[models.Customer], error)
+	RestoreCustomer(ctx context.Context, customerId string) (*models.Customer, error)
+	PurgeCustomer(ctx context.Context, customerId string) (int64, error)
+
+	GetAllPets(ctx context.Context, customerId string, page models.PageRequest) (*models.Page[models.Pet], error)
+	GetPetById(ctx context.Context, customerId string, petId string) (*models.Pet, error)
+	AddPet(ctx context.Context, pet *models.Pet) (*models.Pet, error)
+	UpdatePet(ctx context.Context, pet *models.Pet) (*models.Pet, error)
+	PatchPet(ctx context.Context, customerId string, petId string, version int, apply func(pet *models.Pet) (*models.Pet, error)) (*models.Pet, error)
+	DeletePet(ctx context.Context, customerId string, petId string, version int) (int64, error)
+
+	SearchServices(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Service]], error)
+	GetAllServices(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Service], error)
 	GetServiceById(ctx context.Context, serviceId string) (*models.Service, error)
 	AddService(ctx context.Context, service *models.Service) (*models.Service, error)
 	UpdateService(ctx context.Context, service *models.Service) (*models.Service, error)
-	DeleteService(ctx context.Context, serviceid string) (int64, error)
-
-	GetAllVendors(ctx context.Context, pageIndex string, pageSize string) ([]models.Vendor, error)
+	PatchService(ctx context.Context, serviceId string, version int, apply func(service *models.Service) (*models.Service, error)) (*models.Service, error)
+	DeleteService(ctx context.Context, serviceid string, version int) (int64, error)
+	BatchServices(ctx context.Context, batch *models.Batch[models.Service]) ([]models.BatchResult[models.Service], error)
+	ExportServices(ctx context.Context, filters []string, each func(service *models.Service) error) error
+	ImportServices(ctx context.Context, rows []models.ImportRow[models.Service], options models.ImportOptions) ([]models.BatchResult[models.Service], error)
+	RestoreService(ctx context.Context, serviceId string) (*models.Service, error)
+	PurgeService(ctx context.Context, serviceId string) (int64, error)
+
+	SearchVendors(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Vendor]], error)
+	GetAllVendors(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Vendor], error)
 	GetVendorById(ctx context.Context, vendorId string) (*models.Vendor, error)
 	AddVendor(ctx context.Context, vendor *models.Vendor) (*models.Vendor, error)
 	UpdateVendor(ctx context.Context, vendor *models.Vendor) (*models.Vendor, error)
-	DeleteVendor(ctx context.Context, vendorId string) (int64, error)
+	PatchVendor(ctx context.Context, vendorId string, version int, apply func(vendor *models.Vendor) (*models.Vendor, error)) (*models.Vendor, error)
+	DeleteVendor(ctx context.Context, vendorId string, cascade bool, version int) (int64, error)
+	BatchVendors(ctx context.Context, batch *models.Batch[models.Vendor]) ([]models.BatchResult[models.Vendor], error)
+	ExportVendors(ctx context.Context, filters []string, each func(vendor *models.Vendor) error) error
+	ImportVendors(ctx context.Context, rows []models.ImportRow[models.Vendor], options models.ImportOptions) ([]models.BatchResult[models.Vendor], error)
+	RestoreVendor(ctx context.Context, vendorId string) (*models.Vendor, error)
+	PurgeVendor(ctx context.Context, vendorId string) (int64, error)
+
+	GetAllPurchaseOrders(ctx context.Context, vendorId string, status string, page models.PageRequest) (*models.Page[models.PurchaseOrder], error)
+	GetPurchaseOrderById(ctx context.Context, vendorId string, purchaseOrderId string) (*models.PurchaseOrder, error)
+	AddPurchaseOrder(ctx context.Context, purchaseOrder *models.PurchaseOrder) (*models.PurchaseOrder, error)
+	UpdatePurchaseOrderStatus(ctx context.Context, vendorId string, purchaseOrderId string, status string, version int) (*models.PurchaseOrder, error)
+	ReceivePurchaseOrder(ctx context.Context, vendorId string, purchaseOrderId string, receipts []models.PurchaseOrderReceipt) (*models.PurchaseOrder, error)
+
+	GetAllOrders(ctx context.Context, customerId string, page models.PageRequest) (*models.Page[models.Order], error)
+	GetOrderById(ctx context.Context, orderId string) (*models.Order, error)
+	AddOrder(ctx context.Context, order *models.Order) (*models.Order, error)
+	DeleteOrder(ctx context.Context, orderId string, version int) (int64, error)
+
+	GetAllAppointments(ctx context.Context, day string, resource string, customerId string, page models.PageRequest) (*models.Page[models.Appointment], error)
+	GetAppointmentById(ctx context.Context, appointmentId string) (*models.Appointment, error)
+	GetAvailability(ctx context.Context, resource string, day string, duration string) ([]models.TimeSlot, error)
+	AddAppointment(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error)
+	DeleteAppointment(ctx context.Context, appointmentId string, version int) (int64, error)
+
+	GetAllRoles(ctx context.Context, page models.PageRequest) (*models.Page[models.Role], error)
+	GetRoleById(ctx context.Context, roleId string) (*models.Role, error)
+	AddRole(ctx context.Context, role *models.Role) (*models.Role, error)
+	UpdateRole(ctx context.Context, role *models.Role) (*models.Role, error)
+	DeleteRole(ctx context.Context, roleId string, version int) (int64, error)
+	GetRoleAssignments(ctx context.Context, roleId string, page models.PageRequest) (*models.Page[models.RoleAssignment], error)
+	AddRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) (*models.RoleAssignment, error)
+	DeleteRoleAssignment(ctx context.Context, roleId string, subject string) (int64, error)
+	GetPermissions(ctx context.Context, subject string) ([]string, error)
+
+	GetAllAPIKeys(ctx context.Context, page models.PageRequest) (*models.Page[models.APIKey], error)
+	GetAPIKeyById(ctx context.Context, apiKeyId string) (*models.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	AddAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, apiKeyId string) (*models.APIKey, error)
+	TouchAPIKey(ctx context.Context, apiKeyId uuid.UUID) error
+
+	GetAuditEntries(ctx context.Context, entity string, entityId string, page models.PageRequest) (*models.Page[models.AuditEntry], error)
+
+	PublishEvents(ctx context.Context, limit int, publish func(ctx context.Context, event *models.Event) error) (int, bool, error)
 }
 
 type Client struct {
 	DB *gorm.DB
 }
 
-//go:embed data.sql
-var fdat embed.FS
+// NewDatabaseClient connects to the database described by cfg and, unless
+// the auto_migrate feature is off, brings its schema up to date.
+func NewDatabaseClient(cfg *config.Config) (DatabaseClient, error) {
+	db, err := Open(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
 
-func NewDatabaseClient() (DatabaseClient, error) {
+	client := Client{
+		DB: db,
+	}
 
-	// Retrieve environment variables
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
+	if !cfg.Features.AutoMigrate {
+		return client, nil
+	}
 
-	// Create the connection string using environment variables
-	dsn := fmt.Sprintf("user=%s password=%s dbname=%s host=%s port=%s sslmode=disable",
-		dbUser, dbPassword, dbName, dbHost, dbPort)
+	// Bring the schema up to date before serving requests
+	migrator, err := migrations.NewMigrator(client.DB)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Up(context.Background(), 0); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+// Open connects to the database described by cfg and sizes its pool.
+func Open(cfg config.Database) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn(cfg)), &gorm.Config{
 		// NamingStrategy: schema.NamingStrategy{
 		// 	TablePrefix: "wisdom.",
 		// },
@@ -71,29 +165,61 @@ func NewDatabaseClient() (DatabaseClient, error) {
 			return time.Now().UTC()
 		},
 		QueryFields: true,
+		// Map unique and foreign key violations to gorm.ErrDuplicatedKey
+		// and gorm.ErrForeignKeyViolated
+		TranslateError: true,
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	if err := registerAudit(db); err != nil {
+		return nil, err
+	}
+
 	// Configure the connection pool
 	SqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to get the underlying database object:", err)
+		return nil, fmt.Errorf("failed to get the underlying database object: %w", err)
 	}
-	SqlDB.SetMaxOpenConns(1000)
-	SqlDB.SetMaxIdleConns(10)
-	SqlDB.SetConnMaxLifetime(time.Minute * 5)
+	SqlDB.SetMaxOpenConns(cfg.Pool.MaxOpenConns)
+	SqlDB.SetMaxIdleConns(cfg.Pool.MaxIdleConns)
+	SqlDB.SetConnMaxLifetime(cfg.Pool.ConnMaxLifetime)
+	SqlDB.SetConnMaxIdleTime(cfg.Pool.ConnMaxIdleTime)
 
-	client := Client{
-		DB: db,
-	}
+	return db, nil
+}
 
-	// Migrate the schema and seed data
-	MigrateAndSeedData(client.DB)
+// dsn is the keyword/value connection string for cfg. Values are quoted so
+// passwords may hold spaces and quotes.
+func dsn(cfg config.Database) string {
+	settings := []string{
+		"user=" + quoteDSN(cfg.User),
+		"password=" + quoteDSN(cfg.Password),
+		"dbname=" + quoteDSN(cfg.Name),
+		"host=" + quoteDSN(cfg.Host),
+		"port=" + strconv.Itoa(cfg.Port),
+		"sslmode=" + cfg.SSLMode,
+	}
+	if cfg.SSLRootCert != "" {
+		settings = append(settings, "sslrootcert="+quoteDSN(cfg.SSLRootCert))
+	}
+	if cfg.ConnectTimeout > 0 {
+		// connect_timeout is in whole seconds; round up so 500ms is not none
+		seconds := (cfg.ConnectTimeout + time.Second - 1) / time.Second
+		settings = append(settings, "connect_timeout="+strconv.Itoa(int(seconds)))
+	}
+	if cfg.StatementTimeout > 0 {
+		// Unknown keys become run-time parameters of every session
+		settings = append(settings, "statement_timeout="+strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+	return strings.Join(settings, " ")
+}
 
-	return client, nil
+// quoteDSN quotes a connection string value the way libpq expects.
+func quoteDSN(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
 func (c Client) Ready() bool {
@@ -108,45 +234,20 @@ func (c Client) Ready() bool {
 	return false
 }
 
-func MigrateAndSeedData(DB *gorm.DB) {
-	// Create the wisdom schema
-	var dbErr = DB.Exec("CREATE SCHEMA IF NOT EXISTS wisdom;").Error
-	if dbErr != nil {
-		log.Fatal("Failed to create wisdom schema:", dbErr)
-	}
-
-	// Enable the pgcrypto extension
-	dbErr = DB.Exec(`CREATE EXTENSION IF NOT EXISTS "pgcrypto";`).Error
-	if dbErr != nil {
-		log.Fatal("Failed to enable pgcrypto extension:", dbErr)
-	}
-
-	// Automatically migrate the schema
-	err := DB.AutoMigrate(&models.Customer{}, &models.Vendor{}, &models.Product{}, &models.Service{})
-
+// Close closes the connection pool once the requests using it are done.
+func (c Client) Close() error {
+	sqlDB, err := c.DB.DB()
 	if err != nil {
-		log.Fatalf("Failed to migrate schema: %s", err)
-	}
-
-	// Check if there is any product data
-	var product models.Product
-	result := DB.First(&product)
-	if result.Error == nil && result.RowsAffected > 0 {
-		return // Data already exists
-	}
-
-	// Seed data if needed (example logic)
-	// Read the Data SQL file and execute the commands
-
-	dataSqlBytes, err := fdat.ReadFile("data.sql")
-
-	if err != nil {
-		log.Fatalf("Failed to read SQL file: %s", err)
+		return err
 	}
+	return sqlDB.Close()
+}
 
-	dataSqlQuery := string(dataSqlBytes)
-	err = DB.Exec(dataSqlQuery).Error
-	if err != nil {
-		log.Fatalf("Failed to execute SQL script: %s", err)
+// withDeleted lifts the soft-delete scope when includeDeleted is set so
+// listings can show deleted rows alongside live ones.
+func withDeleted(db *gorm.DB, includeDeleted bool) *gorm.DB {
+	if includeDeleted {
+		return db.Unscoped()
 	}
+	return db
 }
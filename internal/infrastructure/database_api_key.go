@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiKeyUseGranularity is how stale last_used_at may get, so busy keys are
+// not written on every request.
+const apiKeyUseGranularity = time.Minute
+
+// apiKeyListing lists API keys, newest first.
+var apiKeyListing = listing{
+	Fields: map[string]field{
+		"name":         {Column: "name", Type: "text"},
+		"prefix":       {Column: "prefix", Type: "text"},
+		"created_by":   {Column: "created_by", Type: "text", Nullable: true},
+		"created_at":   {Column: "created_at", Type: "timestamptz", Nullable: true},
+		"expires_at":   {Column: "expires_at", Type: "timestamptz", Nullable: true},
+		"last_used_at": {Column: "last_used_at", Type: "timestamptz", Nullable: true},
+		"revoked_at":   {Column: "revoked_at", Type: "timestamptz", Nullable: true},
+	},
+	Sort: "-created_at",
+	ID:   "api_key_id",
+}
+
+func (c Client) GetAllAPIKeys(ctx context.Context, page models.PageRequest) (*models.Page[models.APIKey], error) {
+	return paginate[models.APIKey](c.DB.WithContext(ctx), page, apiKeyListing)
+}
+
+func (c Client) GetAPIKeyById(ctx context.Context, apiKeyId string) (*models.APIKey, error) {
+	parsedUUID, err := dberrors.ParseID(apiKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{}
+	result := c.DB.WithContext(ctx).Where("api_key_id = ?", parsedUUID).First(&key)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "API key", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByPrefix finds the key a client presents by its prefix. It
+// returns nil when no key has the prefix.
+func (c Client) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	result := c.DB.WithContext(ctx).Where("prefix = ?", prefix).Take(&key)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return key, nil
+}
+
+func (c Client) AddAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	key.APIKeyID = uuid.Must(uuid.NewRandom())
+
+	result := c.DB.WithContext(ctx).Create(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: "an API key with prefix " + key.Prefix + " already exists"}
+		}
+		return nil, result.Error
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey stops a key from being accepted. The key is kept so its use
+// can still be traced; revoking it again changes nothing.
+func (c Client) RevokeAPIKey(ctx context.Context, apiKeyId string) (*models.APIKey, error) {
+	parsedUUID, err := dberrors.ParseID(apiKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{APIKeyID: parsedUUID}
+	result := c.DB.WithContext(ctx).
+		Model(&key).
+		Clauses(clause.Returning{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", gorm.Expr("now()"))
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Either there is no such key or it was revoked already
+		return c.GetAPIKeyById(ctx, apiKeyId)
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey records that a key was just used.
+func (c Client) TouchAPIKey(ctx context.Context, apiKeyId uuid.UUID) error {
+	return c.DB.WithContext(ctx).
+		Model(&models.APIKey{APIKeyID: apiKeyId}).
+		Where("last_used_at IS NULL OR last_used_at < ?", time.Now().Add(-apiKeyUseGranularity)).
+		Update("last_used_at", gorm.Expr("now()")).
+		Error
+}
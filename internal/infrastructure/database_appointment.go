@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// Opening hours (UTC) used to compute the free slots of a day.
+const (
+	openingHour = 9
+	closingHour = 17
+)
+
+// maxAppointmentMinutes is the longest booking: a whole working day.
+const maxAppointmentMinutes = (closingHour - openingHour) * 60
+
+// exclusionViolation is the SQLSTATE Postgres reports when a row breaks an
+// exclusion constraint, such as two bookings of one resource overlapping.
+const exclusionViolation = "23P01"
+
+// appointmentListing lists appointments by start time.
+var appointmentListing = listing{
+	Fields: map[string]field{
+		"starts_at":        {Column: "starts_at", Type: "timestamptz"},
+		"ends_at":          {Column: "ends_at", Type: "timestamptz"},
+		"duration_minutes": {Column: "duration_minutes", Type: "integer"},
+		"resource":         {Column: "resource", Type: "text"},
+		"customer_id":      {Column: "customer_id", Type: "uuid"},
+		"service_id":       {Column: "service_id", Type: "uuid"},
+	},
+	Sort: "starts_at",
+	ID:   "appointment_id",
+}
+
+func (c Client) GetAllAppointments(ctx context.Context, day string, resource string, customerId string, page models.PageRequest) (*models.Page[models.Appointment], error) {
+	query := c.DB.WithContext(ctx)
+	if day != "" {
+		start, end, err := dayBounds(day)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("starts_at < ? AND ends_at > ?", end, start)
+	}
+	if resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+	if customerId != "" {
+		parsedUUID, err := dberrors.ParseID(customerId)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("customer_id = ?", parsedUUID)
+	}
+
+	return paginate[models.Appointment](query, page, appointmentListing)
+}
+
+func (c Client) GetAppointmentById(ctx context.Context, appointmentId string) (*models.Appointment, error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(appointmentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	appointment := &models.Appointment{}
+	result := c.DB.WithContext(ctx).Where("appointment_id = ?", parsedUUID).First(&appointment)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "appointment", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return appointment, result.Error
+}
+
+// GetAvailability returns the free intervals of a resource on the given day
+// (YYYY-MM-DD) that are at least duration minutes long.
+func (c Client) GetAvailability(ctx context.Context, resource string, day string, duration string) ([]models.TimeSlot, error) {
+	if resource == "" {
+		resource = models.DefaultAppointmentResource
+	}
+
+	minutes, err := strconv.Atoi(duration)
+	if err != nil || minutes < 1 {
+		minutes = 1
+	}
+	minLength := time.Duration(minutes) * time.Minute
+
+	dayStart, _, err := dayBounds(day)
+	if err != nil {
+		return nil, err
+	}
+	opensAt := dayStart.Add(openingHour * time.Hour)
+	closesAt := dayStart.Add(closingHour * time.Hour)
+
+	var booked []models.Appointment
+	result := c.DB.WithContext(ctx).
+		Where("resource = ? AND starts_at < ? AND ends_at > ?", resource, closesAt, opensAt).
+		Order("starts_at").
+		Find(&booked)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	slots := []models.TimeSlot{}
+	cursor := opensAt
+	for _, appointment := range booked {
+		if appointment.StartsAt.Sub(cursor) >= minLength {
+			slots = append(slots, models.TimeSlot{Start: cursor, End: appointment.StartsAt})
+		}
+		if appointment.EndsAt.After(cursor) {
+			cursor = appointment.EndsAt
+		}
+	}
+	if closesAt.Sub(cursor) >= minLength {
+		slots = append(slots, models.TimeSlot{Start: cursor, End: closesAt})
+	}
+
+	return slots, nil
+}
+
+func (c Client) AddAppointment(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
+	if appointment.StartsAt.IsZero() {
+		return nil, &dberrors.ValidationError{Field: "starts_at", Message: "is required"}
+	}
+	if appointment.DurationMinutes < 1 {
+		return nil, &dberrors.ValidationError{Field: "duration_minutes", Message: "must be at least 1"}
+	}
+	if appointment.DurationMinutes > maxAppointmentMinutes {
+		return nil, &dberrors.ValidationError{Field: "duration_minutes", Message: fmt.Sprintf("must be at most %d", maxAppointmentMinutes)}
+	}
+	if appointment.Resource == "" {
+		appointment.Resource = models.DefaultAppointmentResource
+	}
+
+	appointment.AppointmentID = uuid.Must(uuid.NewRandom())
+	appointment.Version = 1
+	appointment.StartsAt = appointment.StartsAt.UTC()
+	appointment.EndsAt = appointment.StartsAt.Add(time.Duration(appointment.DurationMinutes) * time.Minute)
+
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", appointment.CustomerID).First(&models.Customer{}).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &dberrors.InvalidReferenceError{Entity: "customer", ID: appointment.CustomerID}
+			}
+			return err
+		}
+
+		if err := tx.Where("service_id = ?", appointment.ServiceID).First(&models.Service{}).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &dberrors.InvalidReferenceError{Entity: "service", ID: appointment.ServiceID}
+			}
+			return err
+		}
+
+		if appointment.PetID != nil {
+			pet := tx.Where("pet_id = ? AND customer_id = ?", *appointment.PetID, appointment.CustomerID)
+			if err := pet.First(&models.Pet{}).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return &dberrors.InvalidReferenceError{Entity: "pet", ID: *appointment.PetID}
+				}
+				return err
+			}
+		}
+
+		// Serialise bookings per resource so two concurrent requests cannot
+		// both pass the overlap check below.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", appointment.Resource).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		err := tx.Model(&models.Appointment{}).
+			Where("resource = ? AND starts_at < ? AND ends_at > ?", appointment.Resource, appointment.EndsAt, appointment.StartsAt).
+			Count(&overlapping).Error
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return &dberrors.ConflictError{Message: "the requested time overlaps an existing booking for " + appointment.Resource}
+		}
+
+		return tx.Create(&appointment).Error
+	})
+
+	if err != nil {
+		// The exclusion constraint catches bookings the lock did not serialise
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+			return nil, &dberrors.ConflictError{Message: "the requested time overlaps an existing booking for " + appointment.Resource}
+		}
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// DeleteAppointment cancels an appointment that is still at version; zero
+// cancels whatever is current.
+func (c Client) DeleteAppointment(ctx context.Context, appointmentId string, version int) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(appointmentId)
+
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Appointment](tx, "appointment", parsedUUID, version); err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Appointment{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// dayBounds parses a YYYY-MM-DD date and returns the UTC start of that day
+// and of the next one.
+func dayBounds(day string) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, &dberrors.ValidationError{Field: "date", Message: "must be formatted as YYYY-MM-DD"}
+	}
+	return start, start.AddDate(0, 0, 1), nil
+}
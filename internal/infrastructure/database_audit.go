@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// auditListing lists audit entries, most recent first.
+var auditListing = listing{
+	Fields: map[string]field{
+		"occurred_at": {Column: "occurred_at", Type: "timestamptz"},
+		"actor":       {Column: "actor", Type: "text", Nullable: true},
+		"action":      {Column: "action", Type: "text"},
+		"entity":      {Column: "entity", Type: "text"},
+		"entity_id":   {Column: "entity_id", Type: "text"},
+	},
+	Sort: "-occurred_at",
+	ID:   "audit_id",
+}
+
+// GetAuditEntries lists the recorded changes, optionally only those of one
+// entity, such as product, and of one record of it.
+func (c Client) GetAuditEntries(ctx context.Context, entity string, entityId string, page models.PageRequest) (*models.Page[models.AuditEntry], error) {
+	query := c.DB.WithContext(ctx)
+
+	if entity != "" {
+		known := false
+		for _, audited := range auditedTables {
+			known = known || audited == entity
+		}
+		if !known {
+			return nil, &dberrors.InvalidQueryError{Parameter: "entity", Message: "is not an audited entity"}
+		}
+		query = query.Where(models.AuditEntry{Entity: entity})
+	}
+	if entityId != "" {
+		query = query.Where(models.AuditEntry{EntityID: entityId})
+	}
+
+	return paginate[models.AuditEntry](query, page, auditListing)
+}
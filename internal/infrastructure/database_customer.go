@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
@@ -12,34 +11,28 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func (c Client) GetAllCustomers(ctx context.Context, email string, pageIndex string, pageSize string) ([]models.Customer, error) {
-	// Default values for page and pageSize
-	page, err := strconv.Atoi(pageIndex)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	pSize, err := strconv.Atoi(pageSize)
-	if err != nil || pSize < 1 {
-		pSize = 10
-	} else if pSize > 100 {
-		pSize = 100
-	}
-
-	// Calculate the offset for SQL query
-	offset := (page - 1) * pSize
+// customerListing lists customers by first and last name.
+var customerListing = listing{
+	Fields: map[string]field{
+		"first_name": {Column: "first_name", Type: "text", Nullable: true},
+		"last_name":  {Column: "last_name", Type: "text", Nullable: true},
+		"email":      {Column: "email", Type: "text", Nullable: true},
+		"phone":      {Column: "phone", Type: "text", Nullable: true},
+		"address":    {Column: "address", Type: "text", Nullable: true},
+	},
+	Sort: "first_name,last_name",
+	ID:   "customer_id",
+}
 
-	var customers []models.Customer
-	result := c.DB.WithContext(ctx).
-		Where(models.Customer{Email: email}).
-		Limit(pSize).Offset(offset).Order("first_name").
-		Find(&customers)
-	return customers, result.Error
+func (c Client) GetAllCustomers(ctx context.Context, email string, page models.PageRequest, includeDeleted bool) (*models.Page[models.Customer], error) {
+	query := withDeleted(c.DB.WithContext(ctx), includeDeleted).
+		Where(models.Customer{Email: email})
+	return paginate[models.Customer](query, page, customerListing)
 }
 
 func (c Client) GetCustomerById(ctx context.Context, customerId string) (*models.Customer, error) {
 	// Parse the string into a uuid.UUID
-	parsedUUID, err := uuid.Parse(customerId)
+	parsedUUID, err := dberrors.ParseID(customerId)
 
 	if err != nil {
 		return nil, err
@@ -47,7 +40,7 @@ func (c Client) GetCustomerById(ctx context.Context, customerId string) (*models
 
 	// Query the Customer by id
 	customer := &models.Customer{}
-	result := c.DB.WithContext(ctx).Where(models.Customer{CustomerID: parsedUUID}).First(&customer)
+	result := c.DB.WithContext(ctx).Where("customer_id = ?", parsedUUID).First(&customer)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -60,8 +53,15 @@ func (c Client) GetCustomerById(ctx context.Context, customerId string) (*models
 }
 
 func (c Client) AddCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	return addCustomer(c.DB.WithContext(ctx), customer)
+}
+
+// addCustomer does the work of AddCustomer on db, which may already be a
+// transaction such as a batch.
+func addCustomer(db *gorm.DB, customer *models.Customer) (*models.Customer, error) {
 	customer.CustomerID = uuid.Must(uuid.NewRandom())
-	result := c.DB.WithContext(ctx).
+	customer.Version = 1
+	result := db.
 		Create(&customer)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
@@ -73,30 +73,152 @@ func (c Client) AddCustomer(ctx context.Context, customer *models.Customer) (*mo
 }
 
 func (c Client) UpdateCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	return updateCustomer(c.DB.WithContext(ctx), customer)
+}
 
-	result := c.DB.WithContext(ctx).
-		Clauses(clause.Returning{}).
-		Save(&customer)
+// updateCustomer does the work of UpdateCustomer on db. customer.Version is
+// the version the caller last saw; zero updates whatever is current.
+func updateCustomer(db *gorm.DB, customer *models.Customer) (*models.Customer, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		version, err := lockVersion[models.Customer](tx, "customer", customer.CustomerID, customer.Version)
+		if err != nil {
+			return err
+		}
+		customer.Version = version
 
-	if result.Error != nil {
-		return nil, result.Error
+		return tx.
+			Clauses(clause.Returning{}).
+			Save(&customer).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// PatchCustomer writes only the columns apply changes on the stored customer,
+// which must still be at version; zero patches whatever is current.
+func (c Client) PatchCustomer(ctx context.Context, customerId string, version int, apply func(customer *models.Customer) (*models.Customer, error)) (*models.Customer, error) {
+	parsedUUID, err := dberrors.ParseID(customerId)
+	if err != nil {
+		return nil, err
+	}
+	return patchCustomer(c.DB.WithContext(ctx), parsedUUID, version, apply)
+}
+
+// patchCustomer does the work of PatchCustomer on db.
+func patchCustomer(db *gorm.DB, id uuid.UUID, version int, apply func(customer *models.Customer) (*models.Customer, error)) (*models.Customer, error) {
+	var customer *models.Customer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		customer, _, err = patchRecord(tx, "customer", id, version, nil, apply)
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
 	}
 
 	return customer, nil
 }
 
-func (c Client) DeleteCustomer(ctx context.Context, customerId string) (int64, error) {
-	parsedUUID, uuidErr := uuid.Parse(customerId)
+// DeleteCustomer soft-deletes a customer that is still at version; zero
+// deletes whatever is current.
+func (c Client) DeleteCustomer(ctx context.Context, customerId string, version int) (int64, error) {
+	return deleteCustomer(c.DB.WithContext(ctx), customerId, version)
+}
+
+// deleteCustomer does the work of DeleteCustomer on db.
+func deleteCustomer(db *gorm.DB, customerId string, version int) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(customerId)
 
 	if uuidErr != nil {
 		return 0, uuidErr
 	}
 
-	result := c.DB.WithContext(ctx).Delete(&models.Customer{}, parsedUUID)
+	var rowsAffected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Customer](tx, "customer", parsedUUID, version); err != nil {
+			return err
+		}
+
+		// Soft delete: the row stays so orders and appointments keep their customer
+		result := tx.Delete(&models.Customer{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func (c Client) RestoreCustomer(ctx context.Context, customerId string) (*models.Customer, error) {
+	parsedUUID, err := dberrors.ParseID(customerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.DB.WithContext(ctx).Unscoped().
+		Model(&models.Customer{}).
+		Where("customer_id = ? AND deleted_at IS NOT NULL", parsedUUID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
 
 	if result.Error != nil {
-		return 0, result.Error
+		return nil, result.Error
+	}
+	if result.RowsAffected < 1 {
+		return nil, &dberrors.NotFoundError{Entity: "deleted customer", ID: parsedUUID}
+	}
+
+	return c.GetCustomerById(ctx, customerId)
+}
+
+// PurgeCustomer permanently removes a soft-deleted customer and their pets.
+// It is rejected while orders or appointments still reference the customer
+// so their history is never orphaned.
+func (c Client) PurgeCustomer(ctx context.Context, customerId string) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(customerId)
+
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var orders int64
+		if err := tx.Model(&models.Order{}).Where("customer_id = ?", parsedUUID).Count(&orders).Error; err != nil {
+			return err
+		}
+		if orders > 0 {
+			return &dberrors.ReferencedError{Entity: "customer", ID: parsedUUID, Dependents: "orders"}
+		}
+
+		var appointments int64
+		if err := tx.Model(&models.Appointment{}).Where("customer_id = ?", parsedUUID).Count(&appointments).Error; err != nil {
+			return err
+		}
+		if appointments > 0 {
+			return &dberrors.ReferencedError{Entity: "customer", ID: parsedUUID, Dependents: "appointments"}
+		}
+
+		// Pets go with their owner through ON DELETE CASCADE
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Customer{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
 	}
 
-	return result.RowsAffected, result.Error
+	return rowsAffected, nil
 }
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// orderListing lists orders newest first.
+var orderListing = listing{
+	Fields: map[string]field{
+		"ordered_at":  {Column: "ordered_at", Type: "timestamptz"},
+		"customer_id": {Column: "customer_id", Type: "uuid"},
+		"total":       {Column: "total", Type: "numeric", Nullable: true},
+	},
+	Sort: "-ordered_at",
+	ID:   "order_id",
+}
+
+func (c Client) GetAllOrders(ctx context.Context, customerId string, page models.PageRequest) (*models.Page[models.Order], error) {
+	query := c.DB.WithContext(ctx)
+	if customerId != "" {
+		parsedUUID, err := dberrors.ParseID(customerId)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("customer_id = ?", parsedUUID)
+	}
+
+	return paginate[models.Order](query, page, orderListing, "Lines")
+}
+
+func (c Client) GetOrderById(ctx context.Context, orderId string) (*models.Order, error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(orderId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Query the Order by id together with its lines
+	order := &models.Order{}
+	result := c.DB.WithContext(ctx).Preload("Lines").Where("order_id = ?", parsedUUID).First(&order)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "order", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return order, result.Error
+}
+
+func (c Client) AddOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	if len(order.Lines) == 0 {
+		return nil, &dberrors.ValidationError{Field: "lines", Message: "an order needs at least one line"}
+	}
+
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The customer has to exist before anything is sold to them
+		customer := &models.Customer{}
+		if err := tx.Where("customer_id = ?", order.CustomerID).First(&customer).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &dberrors.InvalidReferenceError{Entity: "customer", ID: order.CustomerID}
+			}
+			return err
+		}
+
+		order.OrderID = uuid.Must(uuid.NewRandom())
+		order.OrderedAt = time.Now().UTC()
+		order.Total = 0
+
+		for i := range order.Lines {
+			line := &order.Lines[i]
+			if (line.ProductID == nil) == (line.ServiceID == nil) {
+				return &dberrors.ValidationError{Field: "lines", Message: "each line must reference exactly one of product_id or service_id"}
+			}
+			if line.Quantity < 1 {
+				return &dberrors.ValidationError{Field: "quantity", Message: "must be at least 1"}
+			}
+
+			// Capture the catalogue price at the time of purchase
+			price, err := linePrice(tx, line)
+			if err != nil {
+				return err
+			}
+
+			line.OrderLineID = uuid.Must(uuid.NewRandom())
+			line.OrderID = order.OrderID
+			line.UnitPrice = price
+			order.Total += price * float64(line.Quantity)
+		}
+
+		return tx.Create(&order).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// DeleteOrder deletes an order that is still at version; zero deletes
+// whatever is current.
+func (c Client) DeleteOrder(ctx context.Context, orderId string, version int) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(orderId)
+
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Order](tx, "order", parsedUUID, version); err != nil {
+			return err
+		}
+
+		// Order lines are removed by the ON DELETE CASCADE constraint
+		result := tx.Delete(&models.Order{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// linePrice looks up the current price of the product or service referenced by line.
+func linePrice(tx *gorm.DB, line *models.OrderLine) (float64, error) {
+	if line.ProductID != nil {
+		product := &models.Product{}
+		if err := tx.Where("product_id = ?", *line.ProductID).First(&product).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, &dberrors.InvalidReferenceError{Entity: "product", ID: *line.ProductID}
+			}
+			return 0, err
+		}
+		return product.Price, nil
+	}
+
+	service := &models.Service{}
+	if err := tx.Where("service_id = ?", *line.ServiceID).First(&service).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &dberrors.InvalidReferenceError{Entity: "service", ID: *line.ServiceID}
+		}
+		return 0, err
+	}
+	return service.Price, nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// outboxLock keys the advisory lock held while relaying, so that only one
+// instance of the service publishes at a time and events keep their order.
+const outboxLock = "wisdom.outbox"
+
+// PublishEvents hands up to limit unpublished events, oldest first, to
+// publish and marks those it accepts as published. When an event fails,
+// later events of its aggregate wait for the next call, so each aggregate's
+// events are published in order; an error wrapping
+// models.ErrPublisherUnavailable stops the whole batch, so an outage does
+// not keep the transaction open for every event. An event may be published
+// more than once if marking it fails. It returns how many events it read,
+// which is zero while another instance holds the outbox, and whether any
+// of them failed.
+func (c Client) PublishEvents(ctx context.Context, limit int, publish func(ctx context.Context, event *models.Event) error) (int, bool, error) {
+	read, failed := 0, false
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", outboxLock).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var events []models.Event
+		result := tx.Where("published_at IS NULL").Order("sequence").Limit(limit).Find(&events)
+		if result.Error != nil {
+			return result.Error
+		}
+		read = len(events)
+
+		blocked := map[string]bool{}
+		for i := range events {
+			event := &events[i]
+			aggregate := event.AggregateType + "/" + event.AggregateID
+			if blocked[aggregate] {
+				continue
+			}
+
+			if err := publish(ctx, event); err != nil {
+				failed = true
+				blocked[aggregate] = true
+				message := err.Error()
+				result := tx.Model(event).Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": message,
+				})
+				if result.Error != nil {
+					return result.Error
+				}
+				if errors.Is(err, models.ErrPublisherUnavailable) {
+					return nil
+				}
+				continue
+			}
+
+			result := tx.Model(event).Updates(map[string]interface{}{
+				"published_at": gorm.Expr("now()"),
+				"last_error":   nil,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return read, failed, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// petListing lists a customer's pets by name.
+var petListing = listing{
+	Fields: map[string]field{
+		"name":    {Column: "name", Type: "text"},
+		"species": {Column: "species", Type: "text"},
+		"breed":   {Column: "breed", Type: "text", Nullable: true},
+	},
+	Sort: "name",
+	ID:   "pet_id",
+}
+
+func (c Client) GetAllPets(ctx context.Context, customerId string, page models.PageRequest) (*models.Page[models.Pet], error) {
+	// Make sure the owner exists so an unknown customer is a 404, not an empty list
+	customer, err := c.GetCustomerById(ctx, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := c.DB.WithContext(ctx).Where("customer_id = ?", customer.CustomerID)
+	return paginate[models.Pet](query, page, petListing)
+}
+
+func (c Client) GetPetById(ctx context.Context, customerId string, petId string) (*models.Pet, error) {
+	parsedCustomerUUID, err := dberrors.ParseID(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedUUID, err := dberrors.ParseID(petId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query the Pet by id, scoped to its owner
+	pet := &models.Pet{}
+	result := c.DB.WithContext(ctx).Where("pet_id = ? AND customer_id = ?", parsedUUID, parsedCustomerUUID).First(&pet)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "pet", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return pet, result.Error
+}
+
+func (c Client) AddPet(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
+	if _, err := c.GetCustomerById(ctx, pet.CustomerID.String()); err != nil {
+		return nil, err
+	}
+
+	pet.PetID = uuid.Must(uuid.NewRandom())
+	pet.Version = 1
+	result := c.DB.WithContext(ctx).Create(&pet)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, result.Error
+	}
+	return pet, nil
+}
+
+// UpdatePet saves pet if it is still at pet.Version, or whatever version is
+// current when that is zero.
+func (c Client) UpdatePet(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := tx.Where("customer_id = ?", pet.CustomerID)
+		version, err := lockVersion[models.Pet](owned, "pet", pet.PetID, pet.Version)
+		if err != nil {
+			return err
+		}
+		pet.Version = version
+
+		return tx.
+			Clauses(clause.Returning{}).
+			Save(&pet).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
+	}
+
+	return pet, nil
+}
+
+// PatchPet writes only the columns apply changes on the stored pet, which
+// must belong to the customer and still be at version; zero patches whatever
+// is current. A pet cannot be moved to another customer this way.
+func (c Client) PatchPet(ctx context.Context, customerId string, petId string, version int, apply func(pet *models.Pet) (*models.Pet, error)) (*models.Pet, error) {
+	parsedCustomerUUID, uuidErr := dberrors.ParseID(customerId)
+	if uuidErr != nil {
+		return nil, uuidErr
+	}
+
+	parsedUUID, uuidErr := dberrors.ParseID(petId)
+	if uuidErr != nil {
+		return nil, uuidErr
+	}
+
+	var pet *models.Pet
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The pet is locked and read only if it belongs to the customer
+		owned := tx.Where("customer_id = ?", parsedCustomerUUID).Session(&gorm.Session{})
+		patched, _, err := patchRecord(owned, "pet", parsedUUID, version, []string{"customer_id"}, apply)
+		pet = patched
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
+	}
+
+	return pet, nil
+}
+
+// DeletePet deletes a pet that is still at version; zero deletes whatever is
+// current.
+func (c Client) DeletePet(ctx context.Context, customerId string, petId string, version int) (int64, error) {
+	parsedCustomerUUID, uuidErr := dberrors.ParseID(customerId)
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	parsedUUID, uuidErr := dberrors.ParseID(petId)
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := tx.Where("customer_id = ?", parsedCustomerUUID)
+		if _, err := lockVersion[models.Pet](owned, "pet", parsedUUID, version); err != nil {
+			return err
+		}
+
+		result := tx.
+			Where("customer_id = ?", parsedCustomerUUID).
+			Delete(&models.Pet{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
@@ -12,65 +11,33 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func (c Client) SearchProducts(ctx context.Context, searchterm string, pageindex string, pagesize string) ([]models.Product, error) {
-	// Convert pageindex and pagesize to integers with proper error handling
-	pIndex, err := strconv.Atoi(pageindex)
-	if err != nil || pIndex < 1 {
-		pIndex = 1 // default to first page
-	}
-
-	pSize, err := strconv.Atoi(pagesize)
-	if err != nil || pSize < 1 {
-		pSize = 10 // default page size
-	} else if pSize > 100 {
-		pSize = 100 // maximum page size
-	}
-
-	// Construct the search pattern to match any part of the name
-	searchPattern := "%" + searchterm + "%"
-
-	// Calculate the offset for pagination
-	offset := (pIndex - 1) * pSize
-
-	var products []models.Product
-
-	// Perform the database query with the LIKE pattern and pagination
-	result := c.DB.WithContext(ctx).
-		Where("name LIKE ?", searchPattern).
-		Limit(pSize).
-		Offset(offset).
-		Find(&products)
-
-	// Return the products and any error from the query
-	return products, result.Error
+// productListing lists products by name.
+var productListing = listing{
+	Fields: map[string]field{
+		"name":              {Column: "name", Type: "text"},
+		"price":             {Column: "price", Type: "numeric", Nullable: true},
+		"vendor_id":         {Column: "vendor_id", Type: "uuid"},
+		"quantity_on_hand":  {Column: "quantity_on_hand", Type: "integer"},
+		"reorder_threshold": {Column: "reorder_threshold", Type: "integer"},
+	},
+	Sort: "name",
+	ID:   "product_id",
 }
 
-func (c Client) GetAllProducts(ctx context.Context, pageIndex string, pageSize string) ([]models.Product, error) {
-	// Default values for page and pageSize
-	page, err := strconv.Atoi(pageIndex)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	pSize, err := strconv.Atoi(pageSize)
-	if err != nil || pSize < 1 {
-		pSize = 10
-	} else if pSize > 100 {
-		pSize = 100
-	}
-
-	// Calculate the offset for SQL query
-	offset := (page - 1) * pSize
+// SearchProducts ranks products by how well their name matches searchterm.
+// page.Filters narrow the results, e.g. by vendor_id or a price range.
+func (c Client) SearchProducts(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Product]], error) {
+	return search[models.Product](c.DB.WithContext(ctx), searchterm, page, productListing, productSearch)
+}
 
-	// Query the products table with LIMIT and OFFSET for pagination
-	var products []models.Product
-	result := c.DB.WithContext(ctx).Limit(pSize).Offset(offset).Order("name").Find(&products)
-	return products, result.Error
+func (c Client) GetAllProducts(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Product], error) {
+	query := withDeleted(c.DB.WithContext(ctx), includeDeleted)
+	return paginate[models.Product](query, page, productListing)
 }
 
 func (c Client) GetProductById(ctx context.Context, productId string) (*models.Product, error) {
 	// Parse the string into a uuid.UUID
-	parsedUUID, err := uuid.Parse(productId)
+	parsedUUID, err := dberrors.ParseID(productId)
 
 	if err != nil {
 		return nil, err
@@ -78,7 +45,7 @@ func (c Client) GetProductById(ctx context.Context, productId string) (*models.P
 
 	// Query the product by product_id
 	product := &models.Product{}
-	result := c.DB.WithContext(ctx).Where(models.Product{ProductID: parsedUUID}).First(&product)
+	result := c.DB.WithContext(ctx).Where("product_id = ?", parsedUUID).First(&product)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -90,74 +57,237 @@ func (c Client) GetProductById(ctx context.Context, productId string) (*models.P
 	return product, result.Error
 }
 
-func (c Client) GetAllProductsByVendor(ctx context.Context, vendorID string, pageIndex string, pageSize string) ([]models.Product, error) {
-	// Parse the string into a uuid.UUID
-	parsedUUID, errUUID := uuid.Parse(vendorID)
+func (c Client) GetAllProductsByVendor(ctx context.Context, vendorID string, page models.PageRequest) (*models.Page[models.Product], error) {
+	query, err := c.productsByVendor(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate[models.Product](query, page, productListing)
+}
 
-	// Default values for page and pageSize
-	page, err := strconv.Atoi(pageIndex)
-	if err != nil || page < 1 {
-		page = 1
+// productsByVendor builds the query behind GetAllProductsByVendor so other
+// vendor listings can narrow it further before paging.
+func (c Client) productsByVendor(ctx context.Context, vendorID string) (*gorm.DB, error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(vendorID)
+	if err != nil {
+		return nil, err
 	}
 
-	pSize, err := strconv.Atoi(pageSize)
-	if err != nil || pSize < 1 {
-		pSize = 10
-	} else if pSize > 100 {
-		pSize = 100
+	return c.DB.WithContext(ctx).Where("vendor_id = ?", parsedUUID), nil
+}
+
+func (c Client) AddProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
+	return addProduct(c.DB.WithContext(ctx), product)
+}
+
+// addProduct does the work of AddProduct on db, which may already be a
+// transaction such as a batch.
+func addProduct(db *gorm.DB, product *models.Product) (*models.Product, error) {
+	product.ProductID = uuid.Must(uuid.NewRandom())
+	product.Version = 1
+
+	// Any opening stock goes through the ledger like every other movement
+	openingStock := product.QuantityOnHand
+	product.QuantityOnHand = 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// The foreign key cannot tell a soft-deleted vendor from a live one
+		if err := tx.Where("vendor_id = ?", product.VendorID).First(&models.Vendor{}).Error; err != nil {
+			return err
+		}
+
+		//Create product
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+
+		if openingStock != 0 {
+			movement := &models.StockMovement{ProductID: product.ProductID, Kind: models.StockReceipt, Quantity: openingStock, Reference: "opening stock"}
+			if _, err := recordStockMovement(tx, movement); err != nil {
+				return err
+			}
+			product.QuantityOnHand = movement.BalanceAfter
+		}
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, &dberrors.InvalidReferenceError{Entity: "vendor", ID: product.VendorID}
+		}
+		return nil, err
 	}
 
-	// Calculate the offset for SQL query
-	offset := (page - 1) * pSize
+	return product, nil
+}
+
+func (c Client) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
+	return updateProduct(c.DB.WithContext(ctx), product)
+}
+
+// updateProduct does the work of UpdateProduct on db. product.Version is
+// the version the caller last saw; zero updates whatever is current.
+func updateProduct(db *gorm.DB, product *models.Product) (*models.Product, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		version, err := lockVersion[models.Product](tx, "product", product.ProductID, product.Version)
+		if err != nil {
+			return err
+		}
+		product.Version = version
 
-	// Query the products table with LIMIT and OFFSET for pagination
-	var products []models.Product
-	result := c.DB.WithContext(ctx).Where("vendor_id = ?", parsedUUID).Limit(pSize).Offset(offset).Order("name").Find(&products)
+		if err := tx.Where("vendor_id = ?", product.VendorID).First(&models.Vendor{}).Error; err != nil {
+			return err
+		}
 
-	if errUUID != nil {
-		return products, errUUID
+		// Update product, leaving stock to the movement ledger and the
+		// reorder threshold to PUT /products/{id}/stock
+		return tx.
+			Clauses(clause.Returning{}).
+			Omit("QuantityOnHand", "ReorderThreshold").
+			Save(&product).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, &dberrors.InvalidReferenceError{Entity: "vendor", ID: product.VendorID}
+		}
+		return nil, err
 	}
 
-	return products, result.Error
+	return product, nil
 }
 
-func (c Client) AddProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	product.ProductID = uuid.Must(uuid.NewRandom())
+// PatchProduct writes only the columns apply changes on the stored product,
+// which must still be at version; zero patches whatever is current. Stock is
+// left to the movement ledger.
+func (c Client) PatchProduct(ctx context.Context, productId string, version int, apply func(product *models.Product) (*models.Product, error)) (*models.Product, error) {
+	parsedUUID, err := dberrors.ParseID(productId)
+	if err != nil {
+		return nil, err
+	}
+	return patchProduct(c.DB.WithContext(ctx), parsedUUID, version, apply)
+}
 
-	//Create product
-	result := c.DB.WithContext(ctx).Create(&product)
+// patchProduct does the work of PatchProduct on db.
+func patchProduct(db *gorm.DB, id uuid.UUID, version int, apply func(product *models.Product) (*models.Product, error)) (*models.Product, error) {
+	var product *models.Product
+	var vendorID uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// A new vendor is checked before anything is written
+		checked := func(current *models.Product) (*models.Product, error) {
+			vendorID = current.VendorID
+			patched, err := apply(current)
+			if err != nil {
+				return nil, err
+			}
+			if patched.VendorID != vendorID {
+				vendorID = patched.VendorID
+				if err := tx.Where("vendor_id = ?", vendorID).First(&models.Vendor{}).Error; err != nil {
+					return nil, err
+				}
+			}
+			return patched, nil
+		}
 
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		patched, _, err := patchRecord(tx, "product", id, version, []string{"quantity_on_hand"}, checked)
+		if err != nil {
+			return err
+		}
+		product = patched
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return nil, &dberrors.ConflictError{}
 		}
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, &dberrors.InvalidReferenceError{Entity: "vendor", ID: vendorID}
+		}
+		return nil, err
 	}
 
-	return product, result.Error
+	return product, nil
 }
 
-func (c Client) UpdateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
-	// Update product
-	result := c.DB.WithContext(ctx).
-		Clauses(clause.Returning{}).
-		Save(&product)
+// DeleteProduct soft-deletes a product that is still at version; zero
+// deletes whatever is current.
+func (c Client) DeleteProduct(ctx context.Context, productId string, version int) (int64, error) {
+	return deleteProduct(c.DB.WithContext(ctx), productId, version)
+}
+
+// deleteProduct does the work of DeleteProduct on db.
+func deleteProduct(db *gorm.DB, productId string, version int) (int64, error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, errUUID := dberrors.ParseID(productId)
+
+	if errUUID != nil {
+		return 0, errUUID
+	}
+
+	var rowsAffected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Product](tx, "product", parsedUUID, version); err != nil {
+			return err
+		}
+
+		// Soft delete product
+		result := tx.Delete(&models.Product{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	return rowsAffected, err
+}
+
+func (c Client) RestoreProduct(ctx context.Context, productId string) (*models.Product, error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.DB.WithContext(ctx).Unscoped().
+		Model(&models.Product{}).
+		Where("product_id = ? AND deleted_at IS NOT NULL", parsedUUID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
 
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: "a live product already uses this name"}
+		}
 		return nil, result.Error
 	}
+	if result.RowsAffected < 1 {
+		return nil, &dberrors.NotFoundError{Entity: "deleted product", ID: parsedUUID}
+	}
 
-	return product, result.Error
+	return c.GetProductById(ctx, productId)
 }
 
-func (c Client) DeleteProduct(ctx context.Context, productId string) (int64, error) {
+// PurgeProduct permanently removes a soft-deleted product.
+func (c Client) PurgeProduct(ctx context.Context, productId string) (int64, error) {
 	// Parse the string into a uuid.UUID
-	parsedUUID, errUUID := uuid.Parse(productId)
+	parsedUUID, errUUID := dberrors.ParseID(productId)
 
 	if errUUID != nil {
 		return 0, errUUID
 	}
-	// Update product
-	result := c.DB.WithContext(ctx).Delete(&models.Product{}, parsedUUID)
+
+	result := c.DB.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Product{}, parsedUUID)
+
+	if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+		return 0, &dberrors.ReferencedError{Entity: "product", ID: parsedUUID, Dependents: "orders or purchase orders"}
+	}
 
 	return result.RowsAffected, result.Error
 }
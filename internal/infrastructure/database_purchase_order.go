@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purchaseOrderListing lists purchase orders newest first.
+var purchaseOrderListing = listing{
+	Fields: map[string]field{
+		"status":     {Column: "status", Type: "text"},
+		"created_at": {Column: "created_at", Type: "timestamptz", Nullable: true},
+		"updated_at": {Column: "updated_at", Type: "timestamptz", Nullable: true},
+	},
+	Sort: "-created_at",
+	ID:   "purchase_order_id",
+}
+
+func (c Client) GetAllPurchaseOrders(ctx context.Context, vendorId string, status string, page models.PageRequest) (*models.Page[models.PurchaseOrder], error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(vendorId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := c.DB.WithContext(ctx).Where("vendor_id = ?", parsedUUID).Where(models.PurchaseOrder{Status: status})
+	return paginate[models.PurchaseOrder](query, page, purchaseOrderListing, "Lines")
+}
+
+func (c Client) GetPurchaseOrderById(ctx context.Context, vendorId string, purchaseOrderId string) (*models.PurchaseOrder, error) {
+	parsedVendorUUID, err := dberrors.ParseID(vendorId)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedUUID, err := dberrors.ParseID(purchaseOrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrder := &models.PurchaseOrder{}
+	result := c.DB.WithContext(ctx).
+		Preload("Lines").
+		Where("purchase_order_id = ? AND vendor_id = ?", parsedUUID, parsedVendorUUID).
+		First(&purchaseOrder)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "purchase order", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return purchaseOrder, result.Error
+}
+
+// AddPurchaseOrder raises a draft purchase order. Every line must be for a
+// product supplied by the order's vendor; a zero unit cost defaults to the
+// product's catalogue price.
+func (c Client) AddPurchaseOrder(ctx context.Context, purchaseOrder *models.PurchaseOrder) (*models.PurchaseOrder, error) {
+	if len(purchaseOrder.Lines) == 0 {
+		return nil, &dberrors.ValidationError{Field: "lines", Message: "a purchase order needs at least one line"}
+	}
+
+	if _, err := c.GetVendorById(ctx, purchaseOrder.VendorID.String()); err != nil {
+		return nil, err
+	}
+
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		purchaseOrder.PurchaseOrderID = uuid.Must(uuid.NewRandom())
+		purchaseOrder.Status = models.PurchaseOrderDraft
+		purchaseOrder.Version = 1
+
+		for i := range purchaseOrder.Lines {
+			line := &purchaseOrder.Lines[i]
+			if line.QuantityOrdered < 1 {
+				return &dberrors.ValidationError{Field: "quantity_ordered", Message: "must be at least 1"}
+			}
+			if line.ProductID == uuid.Nil {
+				return &dberrors.ValidationError{Field: "product_id", Message: "is required"}
+			}
+
+			product := &models.Product{}
+			if err := tx.Where("product_id = ?", line.ProductID).First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return &dberrors.InvalidReferenceError{Entity: "product", ID: line.ProductID}
+				}
+				return err
+			}
+			if product.VendorID != purchaseOrder.VendorID {
+				return &dberrors.ValidationError{Field: "product_id", Message: fmt.Sprintf("product %s is not supplied by this vendor", product.ProductID)}
+			}
+
+			line.PurchaseOrderLineID = uuid.Must(uuid.NewRandom())
+			line.PurchaseOrderID = purchaseOrder.PurchaseOrderID
+			line.QuantityReceived = 0
+			if line.UnitCost == 0 {
+				line.UnitCost = product.Price
+			}
+		}
+
+		return tx.Create(&purchaseOrder).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+// UpdatePurchaseOrderStatus moves a purchase order to status, rejecting
+// transitions the state machine does not allow. Receipt statuses can only be
+// reached by receiving goods. The order must still be at version, unless
+// that is zero.
+func (c Client) UpdatePurchaseOrderStatus(ctx context.Context, vendorId string, purchaseOrderId string, status string, version int) (*models.PurchaseOrder, error) {
+	purchaseOrder, err := c.GetPurchaseOrderById(ctx, vendorId, purchaseOrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && purchaseOrder.Version != version {
+		return nil, &dberrors.PreconditionFailedError{Entity: "purchase order", ID: purchaseOrder.PurchaseOrderID, Version: purchaseOrder.Version}
+	}
+
+	if status == models.PurchaseOrderPartiallyReceived || status == models.PurchaseOrderReceived || !purchaseOrder.CanTransition(status) {
+		return nil, &dberrors.InvalidTransitionError{Entity: "purchase order", From: purchaseOrder.Status, To: status}
+	}
+
+	// Only move the order if nobody else has changed it meanwhile
+	result := c.DB.WithContext(ctx).
+		Model(&purchaseOrder).
+		Where("version = ?", purchaseOrder.Version).
+		Clauses(clause.Returning{}).
+		Updates(map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")})
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected < 1 {
+		return nil, &dberrors.ConflictError{Message: "the purchase order was changed by another request"}
+	}
+
+	return purchaseOrder, nil
+}
+
+// ReceivePurchaseOrder books delivered goods against a sent purchase order,
+// records them as stock receipts and moves the order to partially received
+// or received.
+func (c Client) ReceivePurchaseOrder(ctx context.Context, vendorId string, purchaseOrderId string, receipts []models.PurchaseOrderReceipt) (*models.PurchaseOrder, error) {
+	if len(receipts) == 0 {
+		return nil, &dberrors.ValidationError{Field: "receipts", Message: "at least one receipt is required"}
+	}
+
+	existing, err := c.GetPurchaseOrderById(ctx, vendorId, purchaseOrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrder := &models.PurchaseOrder{}
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Re-read the order under lock so concurrent deliveries are applied in turn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Lines").
+			Where("purchase_order_id = ?", existing.PurchaseOrderID).
+			First(&purchaseOrder).Error; err != nil {
+			return err
+		}
+
+		if !purchaseOrder.CanTransition(models.PurchaseOrderPartiallyReceived) {
+			return &dberrors.InvalidTransitionError{Entity: "purchase order", From: purchaseOrder.Status, To: models.PurchaseOrderPartiallyReceived}
+		}
+
+		for _, receipt := range receipts {
+			line := findPurchaseOrderLine(purchaseOrder, receipt.ProductID)
+			if line == nil {
+				return &dberrors.ValidationError{Field: "product_id", Message: fmt.Sprintf("product %s is not on this purchase order", receipt.ProductID)}
+			}
+			if receipt.Quantity < 1 {
+				return &dberrors.ValidationError{Field: "quantity", Message: "must be at least 1"}
+			}
+			if line.QuantityReceived+receipt.Quantity > line.QuantityOrdered {
+				return &dberrors.ValidationError{Field: "quantity", Message: fmt.Sprintf("receiving %d would exceed the %d ordered", receipt.Quantity, line.QuantityOrdered)}
+			}
+
+			line.QuantityReceived += receipt.Quantity
+			if err := tx.Model(line).Update("quantity_received", line.QuantityReceived).Error; err != nil {
+				return err
+			}
+
+			movement := &models.StockMovement{
+				ProductID: receipt.ProductID,
+				Kind:      models.StockReceipt,
+				Quantity:  receipt.Quantity,
+				Reference: "purchase order " + purchaseOrder.PurchaseOrderID.String(),
+			}
+			if _, err := recordStockMovement(tx, movement); err != nil {
+				return err
+			}
+		}
+
+		status := models.PurchaseOrderReceived
+		for _, line := range purchaseOrder.Lines {
+			if line.QuantityReceived < line.QuantityOrdered {
+				status = models.PurchaseOrderPartiallyReceived
+				break
+			}
+		}
+
+		return tx.Model(&purchaseOrder).
+			Clauses(clause.Returning{}).
+			Updates(map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return purchaseOrder, nil
+}
+
+func findPurchaseOrderLine(purchaseOrder *models.PurchaseOrder, productID uuid.UUID) *models.PurchaseOrderLine {
+	for i := range purchaseOrder.Lines {
+		if purchaseOrder.Lines[i].ProductID == productID {
+			return &purchaseOrder.Lines[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// roleListing lists roles by name.
+var roleListing = listing{
+	Fields: map[string]field{
+		"name":        {Column: "name", Type: "text"},
+		"description": {Column: "description", Type: "text", Nullable: true},
+	},
+	Sort: "name",
+	ID:   "role_id",
+}
+
+// roleAssignmentListing lists the subjects holding a role. Within one role
+// the subject is unique, so it breaks ties.
+var roleAssignmentListing = listing{
+	Fields: map[string]field{
+		"subject":    {Column: "subject", Type: "text"},
+		"created_at": {Column: "created_at", Type: "timestamptz", Nullable: true},
+	},
+	Sort:   "subject",
+	ID:     "subject",
+	IDType: "text",
+}
+
+func (c Client) GetAllRoles(ctx context.Context, page models.PageRequest) (*models.Page[models.Role], error) {
+	return paginate[models.Role](c.DB.WithContext(ctx), page, roleListing)
+}
+
+func (c Client) GetRoleById(ctx context.Context, roleId string) (*models.Role, error) {
+	parsedUUID, err := dberrors.ParseID(roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{}
+	result := c.DB.WithContext(ctx).Where("role_id = ?", parsedUUID).First(&role)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "role", ID: parsedUUID}
+		}
+		return nil, result.Error
+	}
+
+	return role, nil
+}
+
+func (c Client) AddRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	role.RoleID = uuid.Must(uuid.NewRandom())
+	role.Version = 1
+
+	result := c.DB.WithContext(ctx).Create(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: "a role named " + role.Name + " already exists"}
+		}
+		return nil, result.Error
+	}
+
+	return role, nil
+}
+
+// UpdateRole replaces a role. role.Version is the version the caller last
+// saw; zero updates whatever is current.
+func (c Client) UpdateRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := lockVersion[models.Role](tx, "role", role.RoleID, role.Version)
+		if err != nil {
+			return err
+		}
+		role.Version = version
+
+		return tx.
+			Clauses(clause.Returning{}).
+			Save(&role).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: "a role named " + role.Name + " already exists"}
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// DeleteRole removes a role that is still at version; zero deletes whatever
+// is current. Roles still assigned to someone cannot be deleted.
+func (c Client) DeleteRole(ctx context.Context, roleId string, version int) (int64, error) {
+	parsedUUID, err := dberrors.ParseID(roleId)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowsAffected int64
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Role](tx, "role", parsedUUID, version); err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Role{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return 0, &dberrors.ReferencedError{Entity: "role", ID: parsedUUID, Dependents: "assignments"}
+		}
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// GetRoleAssignments lists the subjects holding a role.
+func (c Client) GetRoleAssignments(ctx context.Context, roleId string, page models.PageRequest) (*models.Page[models.RoleAssignment], error) {
+	role, err := c.GetRoleById(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := c.DB.WithContext(ctx).Where("role_id = ?", role.RoleID)
+	return paginate[models.RoleAssignment](query, page, roleAssignmentListing)
+}
+
+func (c Client) AddRoleAssignment(ctx context.Context, assignment *models.RoleAssignment) (*models.RoleAssignment, error) {
+	result := c.DB.WithContext(ctx).Create(&assignment)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: assignment.Subject + " already holds this role"}
+		}
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return nil, &dberrors.NotFoundError{Entity: "role", ID: assignment.RoleID}
+		}
+		return nil, result.Error
+	}
+
+	return assignment, nil
+}
+
+func (c Client) DeleteRoleAssignment(ctx context.Context, roleId string, subject string) (int64, error) {
+	parsedUUID, err := dberrors.ParseID(roleId)
+	if err != nil {
+		return 0, err
+	}
+
+	result := c.DB.WithContext(ctx).
+		Where("role_id = ? AND subject = ?", parsedUUID, subject).
+		Delete(&models.RoleAssignment{})
+
+	return result.RowsAffected, result.Error
+}
+
+// GetPermissions returns every permission the roles of subject grant.
+func (c Client) GetPermissions(ctx context.Context, subject string) ([]string, error) {
+	var permissions []string
+	result := c.DB.WithContext(ctx).Raw(`
+		SELECT DISTINCT unnest(roles.permissions)
+		FROM wisdom.roles
+		JOIN wisdom.role_assignments USING (role_id)
+		WHERE role_assignments.subject = ?`, subject).
+		Scan(&permissions)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return permissions, nil
+}
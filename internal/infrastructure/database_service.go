@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
@@ -12,30 +11,29 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func (c Client) GetAllServices(ctx context.Context, pageIndex string, pageSize string) ([]models.Service, error) {
-	// Default values for page and pageSize
-	page, err := strconv.Atoi(pageIndex)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	pSize, err := strconv.Atoi(pageSize)
-	if err != nil || pSize < 1 {
-		pSize = 10
-	}
+// serviceListing lists services by name.
+var serviceListing = listing{
+	Fields: map[string]field{
+		"name":  {Column: "name", Type: "text"},
+		"price": {Column: "price", Type: "numeric", Nullable: true},
+	},
+	Sort: "name",
+	ID:   "service_id",
+}
 
-	// Calculate the offset for SQL query
-	offset := (page - 1) * pSize
+func (c Client) GetAllServices(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Service], error) {
+	query := withDeleted(c.DB.WithContext(ctx), includeDeleted)
+	return paginate[models.Service](query, page, serviceListing)
+}
 
-	// Query the products table with LIMIT and OFFSET for pagination
-	var services []models.Service
-	result := c.DB.WithContext(ctx).Limit(pSize).Offset(offset).Order("name").Find(&services)
-	return services, result.Error
+// SearchServices ranks services by how well their name matches searchterm.
+func (c Client) SearchServices(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Service]], error) {
+	return search[models.Service](c.DB.WithContext(ctx), searchterm, page, serviceListing, serviceSearch)
 }
 
 func (c Client) GetServiceById(ctx context.Context, serviceId string) (*models.Service, error) {
 	// Parse the string into a uuid.UUID
-	parsedUUID, err := uuid.Parse(serviceId)
+	parsedUUID, err := dberrors.ParseID(serviceId)
 
 	if err != nil {
 		return nil, err
@@ -43,7 +41,7 @@ func (c Client) GetServiceById(ctx context.Context, serviceId string) (*models.S
 
 	// Query the Customer by id
 	service := &models.Service{}
-	result := c.DB.WithContext(ctx).Where(models.Service{ServiceID: parsedUUID}).First(&service)
+	result := c.DB.WithContext(ctx).Where("service_id = ?", parsedUUID).First(&service)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -56,10 +54,17 @@ func (c Client) GetServiceById(ctx context.Context, serviceId string) (*models.S
 }
 
 func (c Client) AddService(ctx context.Context, service *models.Service) (*models.Service, error) {
+	return addService(c.DB.WithContext(ctx), service)
+}
+
+// addService does the work of AddService on db, which may already be a
+// transaction such as a batch.
+func addService(db *gorm.DB, service *models.Service) (*models.Service, error) {
 	service.ServiceID = uuid.Must(uuid.NewRandom())
+	service.Version = 1
 
 	//Create product
-	result := c.DB.WithContext(ctx).Create(&service)
+	result := db.Create(&service)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
@@ -71,28 +76,135 @@ func (c Client) AddService(ctx context.Context, service *models.Service) (*model
 }
 
 func (c Client) UpdateService(ctx context.Context, service *models.Service) (*models.Service, error) {
-	// Update product
-	result := c.DB.WithContext(ctx).
-		Clauses(clause.Returning{}).
-		Save(&service)
+	return updateService(c.DB.WithContext(ctx), service)
+}
+
+// updateService does the work of UpdateService on db. service.Version is
+// the version the caller last saw; zero updates whatever is current.
+func updateService(db *gorm.DB, service *models.Service) (*models.Service, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		version, err := lockVersion[models.Service](tx, "service", service.ServiceID, service.Version)
+		if err != nil {
+			return err
+		}
+		service.Version = version
+
+		return tx.
+			Clauses(clause.Returning{}).
+			Save(&service).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// PatchService writes only the columns apply changes on the stored service,
+// which must still be at version; zero patches whatever is current.
+func (c Client) PatchService(ctx context.Context, serviceId string, version int, apply func(service *models.Service) (*models.Service, error)) (*models.Service, error) {
+	parsedUUID, err := dberrors.ParseID(serviceId)
+	if err != nil {
+		return nil, err
+	}
+	return patchService(c.DB.WithContext(ctx), parsedUUID, version, apply)
+}
+
+// patchService does the work of PatchService on db.
+func patchService(db *gorm.DB, id uuid.UUID, version int, apply func(service *models.Service) (*models.Service, error)) (*models.Service, error) {
+	var service *models.Service
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		service, _, err = patchRecord(tx, "service", id, version, nil, apply)
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{}
+		}
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// DeleteService soft-deletes a service that is still at version; zero
+// deletes whatever is current.
+func (c Client) DeleteService(ctx context.Context, serviceid string, version int) (int64, error) {
+	return deleteService(c.DB.WithContext(ctx), serviceid, version)
+}
+
+// deleteService does the work of DeleteService on db.
+func deleteService(db *gorm.DB, serviceid string, version int) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(serviceid)
+
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Service](tx, "service", parsedUUID, version); err != nil {
+			return err
+		}
+
+		// Soft delete service
+		result := tx.Delete(&models.Service{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func (c Client) RestoreService(ctx context.Context, serviceId string) (*models.Service, error) {
+	parsedUUID, err := dberrors.ParseID(serviceId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.DB.WithContext(ctx).Unscoped().
+		Model(&models.Service{}).
+		Where("service_id = ? AND deleted_at IS NOT NULL", parsedUUID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
 
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, &dberrors.ConflictError{Message: "a live service already uses this name"}
+		}
 		return nil, result.Error
 	}
+	if result.RowsAffected < 1 {
+		return nil, &dberrors.NotFoundError{Entity: "deleted service", ID: parsedUUID}
+	}
 
-	return service, result.Error
+	return c.GetServiceById(ctx, serviceId)
 }
 
-func (c Client) DeleteService(ctx context.Context, serviceid string) (int64, error) {
-	parsedUUID, uuidErr := uuid.Parse(serviceid)
+// PurgeService permanently removes a soft-deleted service.
+func (c Client) PurgeService(ctx context.Context, serviceId string) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(serviceId)
 
 	if uuidErr != nil {
 		return 0, uuidErr
 	}
 
-	result := c.DB.WithContext(ctx).Delete(&models.Service{}, parsedUUID)
+	result := c.DB.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Service{}, parsedUUID)
 
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return 0, &dberrors.ReferencedError{Entity: "service", ID: parsedUUID, Dependents: "orders or appointments"}
+		}
 		return 0, result.Error
 	}
 
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (c Client) GetProductStock(ctx context.Context, productId string) (*models.StockLevel, error) {
+	product, err := c.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	return product.StockLevel(), nil
+}
+
+// SetReorderThreshold changes a product's reorder threshold if the product
+// is still at version, or whatever version is current when that is zero.
+func (c Client) SetReorderThreshold(ctx context.Context, productId string, threshold int, version int) (*models.StockLevel, error) {
+	if threshold < 0 {
+		return nil, &dberrors.ValidationError{Field: "reorder_threshold", Message: "must not be negative"}
+	}
+
+	parsedUUID, err := dberrors.ParseID(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &models.Product{ProductID: parsedUUID}
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		next, err := lockVersion[models.Product](tx, "product", parsedUUID, version)
+		if err != nil {
+			return err
+		}
+
+		return tx.
+			Model(&product).
+			Clauses(clause.Returning{}).
+			Updates(map[string]interface{}{"reorder_threshold": threshold, "version": next}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return product.StockLevel(), nil
+}
+
+// stockMovementListing lists the stock ledger newest first.
+var stockMovementListing = listing{
+	Fields: map[string]field{
+		"kind":       {Column: "kind", Type: "text"},
+		"quantity":   {Column: "quantity", Type: "integer"},
+		"reference":  {Column: "reference", Type: "text", Nullable: true},
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+	},
+	Sort: "-created_at",
+	ID:   "stock_movement_id",
+}
+
+func (c Client) GetStockMovements(ctx context.Context, productId string, page models.PageRequest) (*models.Page[models.StockMovement], error) {
+	// Parse the string into a uuid.UUID
+	parsedUUID, err := dberrors.ParseID(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := c.DB.WithContext(ctx).Where("product_id = ?", parsedUUID)
+	return paginate[models.StockMovement](query, page, stockMovementListing)
+}
+
+// RecordStockMovement appends a movement to the ledger and applies it to the
+// product's on-hand quantity in the same transaction. Receipts and sales take
+// a positive quantity; adjustments are signed.
+func (c Client) RecordStockMovement(ctx context.Context, movement *models.StockMovement) (*models.StockLevel, error) {
+	var level *models.StockLevel
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		level, err = recordStockMovement(tx, movement)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return level, nil
+}
+
+func (c Client) GetLowStockProductsByVendor(ctx context.Context, vendorID string, page models.PageRequest) (*models.Page[models.Product], error) {
+	query, err := c.productsByVendor(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = query.Where("quantity_on_hand <= reorder_threshold")
+	return paginate[models.Product](query, page, productListing)
+}
+
+// recordStockMovement does the work of RecordStockMovement inside tx so
+// other writes (such as AddProduct) can move stock atomically with their own changes.
+func recordStockMovement(tx *gorm.DB, movement *models.StockMovement) (*models.StockLevel, error) {
+	switch movement.Kind {
+	case models.StockReceipt, models.StockSale:
+		if movement.Quantity < 1 {
+			return nil, &dberrors.ValidationError{Field: "quantity", Message: fmt.Sprintf("must be positive for a %s", movement.Kind)}
+		}
+		if movement.Kind == models.StockSale {
+			movement.Quantity = -movement.Quantity
+		}
+	case models.StockAdjustment:
+		if movement.Quantity == 0 {
+			return nil, &dberrors.ValidationError{Field: "quantity", Message: "must not be zero"}
+		}
+	default:
+		return nil, &dberrors.ValidationError{Field: "kind", Message: "must be one of receipt, sale or adjustment"}
+	}
+
+	// Lock the product row so concurrent movements apply one after another
+	product := &models.Product{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", movement.ProductID).
+		First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &dberrors.NotFoundError{Entity: "product", ID: movement.ProductID}
+		}
+		return nil, err
+	}
+
+	balance := product.QuantityOnHand + movement.Quantity
+	if balance < 0 {
+		return nil, &dberrors.ConflictError{Message: fmt.Sprintf("insufficient stock: %d on hand", product.QuantityOnHand)}
+	}
+
+	if err := tx.Model(&product).Updates(map[string]interface{}{"quantity_on_hand": balance, "version": product.Version + 1}).Error; err != nil {
+		return nil, err
+	}
+
+	movement.StockMovementID = uuid.Must(uuid.NewRandom())
+	movement.BalanceAfter = balance
+	movement.CreatedAt = time.Now().UTC()
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	product.QuantityOnHand = balance
+	return product.StockLevel(), nil
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
@@ -12,30 +11,33 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func (c Client) GetAllVendors(ctx context.Context, pageIndex string, pageSize string) ([]models.Vendor, error) {
-	// Default values for page and pageSize
-	page, err := strconv.Atoi(pageIndex)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	pSize, err := strconv.Atoi(pageSize)
-	if err != nil || pSize < 1 {
-		pSize = 10
-	}
+// vendorListing lists vendors by name.
+var vendorListing = listing{
+	Fields: map[string]field{
+		"name":    {Column: "name", Type: "text"},
+		"contact": {Column: "contact", Type: "text", Nullable: true},
+		"phone":   {Column: "phone", Type: "text", Nullable: true},
+		"email":   {Column: "email", Type: "text", Nullable: true},
+		"address": {Column: "address", Type: "text", Nullable: true},
+	},
+	Sort: "name",
+	ID:   "vendor_id",
+}
 
-	// Calculate the offset for SQL query
-	offset := (page - 1) * pSize
+func (c Client) GetAllVendors(ctx context.Context, page models.PageRequest, includeDeleted bool) (*models.Page[models.Vendor], error) {
+	query := withDeleted(c.DB.WithContext(ctx), includeDeleted)
+	return paginate[models.Vendor](query, page, vendorListing)
+}
 
-	// Query the products table with LIMIT and OFFSET for pagination
-	var vendors []models.Vendor
-	result := c.DB.WithContext(ctx).Limit(pSize).Offset(offset).Order("name").Find(&vendors)
-	return vendors, result.Error
+// SearchVendors ranks vendors by how well their name and contact match
+// searchterm.
+func (c Client) SearchVendors(ctx context.Context, searchterm string, page models.PageRequest) (*models.Page[models.SearchHit[models.Vendor]], error) {
+	return search[models.Vendor](c.DB.WithContext(ctx), searchterm, page, vendorListing, vendorSearch)
 }
 
 func (c Client) GetVendorById(ctx context.Context, vendorId string) (*models.Vendor, error) {
 	// Parse the string into a uuid.UUID
-	parsedUUID, err := uuid.Parse(vendorId)
+	parsedUUID, err := dberrors.ParseID(vendorId)
 
 	if err != nil {
 		return nil, err
@@ -43,7 +45,7 @@ func (c Client) GetVendorById(ctx context.Context, vendorId string) (*models.Ven
 
 	// Query the Vendor by id
 	vendor := &models.Vendor{}
-	result := c.DB.WithContext(ctx).Where(models.Vendor{VendorID: parsedUUID}).First(&vendor)
+	result := c.DB.WithContext(ctx).Where("vendor_id = ?", parsedUUID).First(&vendor)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -56,10 +58,17 @@ func (c Client) GetVendorById(ctx context.Context, vendorId string) (*models.Ven
 }
 
 func (c Client) AddVendor(ctx context.Context, vendor *models.Vendor) (*models.Vendor, error) {
+	return addVendor(c.DB.WithContext(ctx), vendor)
+}
+
+// addVendor does the work of AddVendor on db, which may already be a
+// transaction such as a batch.
+func addVendor(db *gorm.DB, vendor *models.Vendor) (*models.Vendor, error) {
 	vendor.VendorID = uuid.Must(uuid.NewRandom())
+	vendor.Version = 1
 
 	//Create Vendor
-	result := c.DB.WithContext(ctx).Create(&vendor)
+	result := db.Create(&vendor)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
@@ -71,28 +80,142 @@ func (c Client) AddVendor(ctx context.Context, vendor *models.Vendor) (*models.V
 }
 
 func (c Client) UpdateVendor(ctx context.Context, vendor *models.Vendor) (*models.Vendor, error) {
-	// Update Vendor
-	result := c.DB.WithContext(ctx).
-		Clauses(clause.Returning{}).
-		Save(&vendor)
+	return updateVendor(c.DB.WithContext(ctx), vendor)
+}
+
+// updateVendor does the work of UpdateVendor on db. vendor.Version is the
+// version the caller last saw; zero updates whatever is current.
+func updateVendor(db *gorm.DB, vendor *models.Vendor) (*models.Vendor, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		version, err := lockVersion[models.Vendor](tx, "vendor", vendor.VendorID, vendor.Version)
+		if err != nil {
+			return err
+		}
+		vendor.Version = version
+
+		// Update Vendor
+		return tx.
+			Clauses(clause.Returning{}).
+			Save(&vendor).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vendor, nil
+}
+
+// PatchVendor writes only the columns apply changes on the stored vendor,
+// which must still be at version; zero patches whatever is current.
+func (c Client) PatchVendor(ctx context.Context, vendorId string, version int, apply func(vendor *models.Vendor) (*models.Vendor, error)) (*models.Vendor, error) {
+	parsedUUID, err := dberrors.ParseID(vendorId)
+	if err != nil {
+		return nil, err
+	}
+	return patchVendor(c.DB.WithContext(ctx), parsedUUID, version, apply)
+}
+
+// patchVendor does the work of PatchVendor on db.
+func patchVendor(db *gorm.DB, id uuid.UUID, version int, apply func(vendor *models.Vendor) (*models.Vendor, error)) (*models.Vendor, error) {
+	var vendor *models.Vendor
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		vendor, _, err = patchRecord(tx, "vendor", id, version, nil, apply)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vendor, nil
+}
+
+// DeleteVendor soft-deletes a vendor that is still at version, or whatever
+// is current when version is zero. Unless cascade is set it refuses while the
+// vendor still has live products; with cascade those are soft-deleted too.
+func (c Client) DeleteVendor(ctx context.Context, vendorId string, cascade bool, version int) (int64, error) {
+	return deleteVendor(c.DB.WithContext(ctx), vendorId, cascade, version)
+}
+
+// deleteVendor does the work of DeleteVendor on db.
+func deleteVendor(db *gorm.DB, vendorId string, cascade bool, version int) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(vendorId)
+
+	if uuidErr != nil {
+		return 0, uuidErr
+	}
+
+	var rowsAffected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVersion[models.Vendor](tx, "vendor", parsedUUID, version); err != nil {
+			return err
+		}
+
+		if cascade {
+			if err := tx.Where("vendor_id = ?", parsedUUID).Delete(&models.Product{}).Error; err != nil {
+				return err
+			}
+		} else {
+			var products int64
+			if err := tx.Model(&models.Product{}).Where("vendor_id = ?", parsedUUID).Count(&products).Error; err != nil {
+				return err
+			}
+			if products > 0 {
+				return &dberrors.ReferencedError{Entity: "vendor", ID: parsedUUID, Dependents: "products"}
+			}
+		}
+
+		result := tx.Delete(&models.Vendor{}, parsedUUID)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func (c Client) RestoreVendor(ctx context.Context, vendorId string) (*models.Vendor, error) {
+	parsedUUID, err := dberrors.ParseID(vendorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.DB.WithContext(ctx).Unscoped().
+		Model(&models.Vendor{}).
+		Where("vendor_id = ? AND deleted_at IS NOT NULL", parsedUUID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
 
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if result.RowsAffected < 1 {
+		return nil, &dberrors.NotFoundError{Entity: "deleted vendor", ID: parsedUUID}
+	}
 
-	return vendor, result.Error
+	return c.GetVendorById(ctx, vendorId)
 }
 
-func (c Client) DeleteVendor(ctx context.Context, vendorId string) (int64, error) {
-	parsedUUID, uuidErr := uuid.Parse(vendorId)
+// PurgeVendor permanently removes a soft-deleted vendor. Products and
+// purchase orders, deleted or not, still pointing at it make it fail.
+func (c Client) PurgeVendor(ctx context.Context, vendorId string) (int64, error) {
+	parsedUUID, uuidErr := dberrors.ParseID(vendorId)
 
 	if uuidErr != nil {
 		return 0, uuidErr
 	}
 
-	result := c.DB.WithContext(ctx).Delete(&models.Vendor{}, parsedUUID)
+	result := c.DB.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Vendor{}, parsedUUID)
 
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return 0, &dberrors.ReferencedError{Entity: "vendor", ID: parsedUUID, Dependents: "products or purchase orders"}
+		}
 		return 0, result.Error
 	}
 
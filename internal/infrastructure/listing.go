@@ -0,0 +1,163 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"gorm.io/gorm"
+)
+
+// field is a column clients may filter and sort a listing by. Type is the
+// Postgres type values are checked against and cast to. Nullable columns
+// are sorted as their zero value, which is also what a NULL scans into, so
+// cursors can seek past them.
+type field struct {
+	Column   string
+	Type     string
+	Nullable bool
+}
+
+// zeroValues are the values NULLs sort as, by column type.
+var zeroValues = map[string]string{
+	"text":        "''",
+	"numeric":     "0",
+	"integer":     "0",
+	"timestamptz": "'0001-01-01 00:00:00+00'",
+}
+
+// sortKey orders a listing by one field.
+type sortKey struct {
+	field
+	Desc bool
+}
+
+// expression is the SQL the key is sorted and compared by.
+func (k sortKey) expression() string {
+	if k.Nullable {
+		return fmt.Sprintf("COALESCE(%s, CAST(%s AS %s))", k.Column, zeroValues[k.Type], k.Type)
+	}
+	return k.Column
+}
+
+// listing describes how an entity's rows may be filtered, sorted and paged.
+// Fields is the whitelist of names clients may use, keyed by their JSON
+// name, and Sort is the order used when the client asks for none. Rows are
+// ordered by ID last so every row has a unique position; IDType is the
+// column type of ID, uuid when empty.
+type listing struct {
+	Fields map[string]field
+	Sort   string
+	ID     string
+	IDType string
+}
+
+var filterExpression = regexp.MustCompile(`^([a-z_]+)(>=|<=|!=|=|>|<|~)(.*)$`)
+
+var filterOperators = map[string]string{
+	"=":  "=",
+	"!=": "<>",
+	">":  ">",
+	">=": ">=",
+	"<":  "<",
+	"<=": "<=",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filter narrows query by each filter expression. Only whitelisted fields and
+// known operators are accepted and values are always bound as parameters, so
+// a filter cannot inject SQL. ~ matches text fields containing the value.
+func (l listing) filter(query *gorm.DB, filters []string) (*gorm.DB, error) {
+	for _, filter := range filters {
+		match := filterExpression.FindStringSubmatch(filter)
+		if match == nil {
+			return nil, &dberrors.InvalidQueryError{Parameter: "filter", Message: fmt.Sprintf("%q is not of the form field<operator>value", filter)}
+		}
+
+		name, operator, value := match[1], match[2], match[3]
+		f, ok := l.Fields[name]
+		if !ok {
+			return nil, &dberrors.InvalidQueryError{Parameter: "filter", Message: fmt.Sprintf("unknown field %q", name)}
+		}
+
+		if operator == "~" {
+			if f.Type != "text" {
+				return nil, &dberrors.InvalidQueryError{Parameter: "filter", Message: fmt.Sprintf("~ only applies to text fields, not %q", name)}
+			}
+			query = query.Where(fmt.Sprintf("%s ILIKE ?", f.Column), "%"+likeEscaper.Replace(value)+"%")
+			continue
+		}
+
+		parsed, err := parseValue(f, value)
+		if err != nil {
+			return nil, &dberrors.InvalidQueryError{Parameter: "filter", Message: fmt.Sprintf("%s %s", name, err)}
+		}
+		query = query.Where(fmt.Sprintf("%s %s ?", f.Column, filterOperators[operator]), parsed)
+	}
+	return query, nil
+}
+
+// sortKeys parses a sort such as "-price,name" into the keys the listing is
+// ordered by, ending with the ID as tie-breaker.
+func (l listing) sortKeys(sort string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "-"), "+")
+
+		f, ok := l.Fields[name]
+		if !ok {
+			return nil, &dberrors.InvalidQueryError{Parameter: "sort", Message: fmt.Sprintf("unknown field %q", name)}
+		}
+		keys = append(keys, sortKey{field: f, Desc: desc})
+	}
+
+	// The ID follows the direction of the last key so it only breaks ties
+	idType := l.IDType
+	if idType == "" {
+		idType = "uuid"
+	}
+	id := sortKey{field: field{Column: l.ID, Type: idType}, Desc: keys[len(keys)-1].Desc}
+	return append(keys, id), nil
+}
+
+// parseValue checks value against the field's type and converts it to what
+// the column is compared with.
+func parseValue(f field, value string) (interface{}, error) {
+	switch f.Type {
+	case "numeric":
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return parsed, nil
+	case "integer":
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		return parsed, nil
+	case "uuid":
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be a valid id")
+		}
+		return parsed, nil
+	case "timestamptz":
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		if parsed, err := time.Parse(time.DateOnly, value); err == nil {
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("must be an RFC 3339 time or a YYYY-MM-DD date")
+	default:
+		return value, nil
+	}
+}
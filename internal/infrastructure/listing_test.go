@@ -0,0 +1,142 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Filters become bound conditions on whitelisted columns; anything else is
+// refused as an invalid filter before a query is built.
+func TestListingFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		listing listing
+		filters []string
+		want    string
+		invalid string
+	}{
+		{name: "equals", listing: productListing, filters: []string{"name=Kibble"}, want: `name = 'Kibble'`},
+		{name: "not equals", listing: productListing, filters: []string{"name!=Kibble"}, want: `name <> 'Kibble'`},
+		{name: "number", listing: productListing, filters: []string{"price>=12.5"}, want: `price >= 12.5`},
+		{name: "whole number", listing: productListing, filters: []string{"quantity_on_hand<3"}, want: `quantity_on_hand < 3`},
+		{name: "uuid", listing: productListing, filters: []string{"vendor_id=3f1c1a7e-5b1f-4c59-9d3a-0c1e8f1d2b4a"}, want: `vendor_id = '3f1c1a7e-5b1f-4c59-9d3a-0c1e8f1d2b4a'`},
+		{name: "date", listing: appointmentListing, filters: []string{"starts_at>2026-10-17"}, want: `starts_at > '2026-10-17 00:00:00'`},
+		{name: "contains", listing: productListing, filters: []string{"name~dog"}, want: `name ILIKE '%dog%'`},
+		{name: "contains escapes wildcards", listing: productListing, filters: []string{`name~50%_off\`}, want: `name ILIKE '%50\%\_off\\%'`},
+		{name: "combined", listing: productListing, filters: []string{"price>1", "price<5"}, want: `price > 1 AND price < 5`},
+		{name: "value with operator", listing: productListing, filters: []string{"name==x"}, want: `name = '=x'`},
+		{name: "unknown field", listing: productListing, filters: []string{"cost>1"}, invalid: `unknown field "cost"`},
+		{name: "column not exposed", listing: productListing, filters: []string{"deleted_at=2026-10-17"}, invalid: `unknown field "deleted_at"`},
+		{name: "unknown operator", listing: productListing, filters: []string{"price^1"}, invalid: "not of the form"},
+		{name: "no operator", listing: productListing, filters: []string{"price"}, invalid: "not of the form"},
+		{name: "injection in field", listing: productListing, filters: []string{"name;DROP TABLE x=1"}, invalid: "not of the form"},
+		{name: "contains on number", listing: productListing, filters: []string{"price~1"}, invalid: "only applies to text"},
+		{name: "not a number", listing: productListing, filters: []string{"price>cheap"}, invalid: "must be a number"},
+		{name: "not a whole number", listing: productListing, filters: []string{"quantity_on_hand=1.5"}, invalid: "must be a whole number"},
+		{name: "not an id", listing: productListing, filters: []string{"vendor_id=acme"}, invalid: "must be a valid id"},
+		{name: "not a time", listing: appointmentListing, filters: []string{"starts_at>tomorrow"}, invalid: "must be an RFC 3339 time"},
+	}
+
+	db := dryRunDB(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var err error
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var query *gorm.DB
+				query, err = test.listing.filter(tx.Table("listed"), test.filters)
+				if err != nil {
+					return tx
+				}
+				return query.Find(&[]map[string]interface{}{})
+			})
+
+			if test.invalid != "" {
+				checkInvalidQuery(t, err, "filter", test.invalid)
+				return
+			}
+			if err != nil {
+				t.Fatalf("filter(%q) = %v", test.filters, err)
+			}
+			if !strings.Contains(sql, test.want) {
+				t.Errorf("filter(%q) built %s, want it to contain %s", test.filters, sql, test.want)
+			}
+		})
+	}
+}
+
+// A sort lists fields in order, each ascending unless prefixed with -, and
+// always ends with the ID in the direction of the last field.
+func TestListingSortKeys(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    []string
+		invalid string
+	}{
+		{sort: "name", want: []string{"name ASC", "product_id ASC"}},
+		{sort: "+name", want: []string{"name ASC", "product_id ASC"}},
+		{sort: "-price", want: []string{"COALESCE(price, CAST(0 AS numeric)) DESC", "product_id DESC"}},
+		{sort: "-price, name", want: []string{"COALESCE(price, CAST(0 AS numeric)) DESC", "name ASC", "product_id ASC"}},
+		{sort: "name,-quantity_on_hand", want: []string{"name ASC", "quantity_on_hand DESC", "product_id DESC"}},
+		{sort: "cost", invalid: `unknown field "cost"`},
+		{sort: "name,", invalid: `unknown field ""`},
+		{sort: "name;DROP TABLE x", invalid: "unknown field"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			keys, err := productListing.sortKeys(test.sort)
+			if test.invalid != "" {
+				checkInvalidQuery(t, err, "sort", test.invalid)
+				return
+			}
+			if err != nil {
+				t.Fatalf("sortKeys(%q) = %v", test.sort, err)
+			}
+
+			got := make([]string, len(keys))
+			for i, key := range keys {
+				direction := "ASC"
+				if key.Desc {
+					direction = "DESC"
+				}
+				got[i] = key.expression() + " " + direction
+			}
+			if strings.Join(got, ", ") != strings.Join(test.want, ", ") {
+				t.Errorf("sortKeys(%q) = %q, want %q", test.sort, got, test.want)
+			}
+		})
+	}
+}
+
+// checkInvalidQuery fails t unless err is an InvalidQueryError for parameter
+// whose message contains message.
+func checkInvalidQuery(t *testing.T, err error, parameter string, message string) {
+	t.Helper()
+	var invalid *dberrors.InvalidQueryError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want an invalid %s", err, parameter)
+	}
+	if invalid.Parameter != parameter || !strings.Contains(invalid.Message, message) {
+		t.Errorf("error = %v, want an invalid %s mentioning %s", err, parameter, message)
+	}
+}
+
+// dryRunDB builds SQL without a database to run it on.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
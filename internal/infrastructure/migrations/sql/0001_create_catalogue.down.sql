@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS wisdom.products;
+DROP TABLE IF EXISTS wisdom.vendors;
+DROP TABLE IF EXISTS wisdom.customers;
+DROP TABLE IF EXISTS wisdom.services;
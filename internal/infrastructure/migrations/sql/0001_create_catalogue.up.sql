@@ -0,0 +1,33 @@
+CREATE SCHEMA IF NOT EXISTS wisdom;
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+
+CREATE TABLE IF NOT EXISTS wisdom.services (
+      service_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      name TEXT NOT NULL UNIQUE,
+      price NUMERIC (12,2)
+);
+
+CREATE TABLE IF NOT EXISTS wisdom.customers (
+      customer_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      first_name TEXT,
+      last_name TEXT,
+      email TEXT,
+      phone TEXT,
+      address TEXT
+);
+
+CREATE TABLE IF NOT EXISTS wisdom.vendors (
+      vendor_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      name TEXT NOT NULL,
+      contact TEXT,
+      phone TEXT,
+      email TEXT,
+      address TEXT
+);
+
+CREATE TABLE IF NOT EXISTS wisdom.products (
+      product_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      name TEXT NOT NULL UNIQUE,
+      price NUMERIC (12,2),
+      vendor_id UUID NOT NULL
+);
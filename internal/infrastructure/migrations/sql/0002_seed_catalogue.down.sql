@@ -0,0 +1,147 @@
+-- Remove the seeded catalogue rows, leaving anything added since.
+DELETE FROM wisdom.customers WHERE email IN (
+    'penatibus.et@lectusa.com',
+    'nibh@ultricesposuere.edu',
+    'quam.quis.diam@facilisisfacilisis.org',
+    'sit@vitaealiquetnec.net',
+    'sociis.natoque.penatibus@pedeCras.co.uk',
+    'ultrices.Vivamus@orciin.edu',
+    'nulla.ante@posuerevulputate.org',
+    'ut.nisi@elitAliquam.ca',
+    'eget.lacus@sitametorci.org',
+    'montes.nascetur@semperrutrum.net',
+    'auctor.odio@utdolordapibus.co.uk',
+    'pellentesque@Donecest.net',
+    'et.magnis@posuereat.edu',
+    'ipsum@eu.co.uk',
+    'Ut.tincidunt.vehicula@urnajusto.org',
+    'Nullam.suscipit.est@ametornare.com',
+    'Proin.vel.nisl@enim.ca',
+    'metus@dictumplacerataugue.net',
+    'amet.massa@Maurisvestibulumneque.net',
+    'eros@faucibusleo.ca',
+    'adipiscing.elit.Etiam@euultricessit.edu',
+    'dignissim.tempor@tincidunt.edu',
+    'sed@quispedePraesent.edu',
+    'Nullam.ut@tellusjusto.co.uk',
+    'arcu.eu@eusem.com',
+    'per.conubia.nostra@neque.org',
+    'taciti@vehicula.edu',
+    'congue.a@lorem.edu',
+    'faucibus.Morbi@semmagna.org',
+    'purus@luctus.edu',
+    'mauris@consectetuerrhoncus.ca',
+    'tempor.arcu.Vestibulum@tinciduntaliquamarcu.com',
+    'tempor.arcu@ascelerisque.com',
+    'Suspendisse.commodo@egestasrhoncus.ca',
+    'ultricies.ornare.elit@Mauris.ca',
+    'nisl.Maecenas.malesuada@nuncac.org',
+    'ac@Utsemper.net',
+    'ipsum.cursus.vestibulum@semperegestas.com',
+    'Mauris@Proin.co.uk',
+    'sagittis.placerat.Cras@pharetra.edu',
+    'molestie@mattis.edu',
+    'at.auctor@nonvestibulumnec.net',
+    'egestas.a@sapien.edu',
+    'nec@orci.ca',
+    'luctus@nec.co.uk',
+    'blandit@quis.com',
+    'Proin@vitaeerat.co.uk',
+    'ligula.tortor@Curabituregestas.co.uk',
+    'amet.consectetuer.adipiscing@vestibulum.com',
+    'eu.odio@tempor.edu',
+    'Vestibulum.ante.ipsum@sapienimperdiet.edu',
+    'a@nibhdolor.com',
+    'neque.sed@turpis.ca',
+    'Aliquam.fringilla.cursus@necmalesuada.org',
+    'in@dictum.org',
+    'ac.mattis@pretiumaliquet.net',
+    'nec.ante@Donecnibhenim.edu',
+    'sit@ac.org',
+    'arcu@nisiMaurisnulla.co.uk',
+    'tempus.scelerisque@turpisnonenim.co.uk',
+    'vitae.nibh.Donec@ideratEtiam.org',
+    'orci.luctus@semut.org',
+    'convallis.convallis.dolor@aenim.co.uk',
+    'ipsum.Suspendisse@euerosNam.ca',
+    'mauris.erat@cursusdiamat.net',
+    'rhoncus@facilisis.co.uk',
+    'massa.Mauris.vestibulum@In.co.uk',
+    'neque.sed@ultriciessemmagna.ca',
+    'arcu.Morbi@ut.co.uk',
+    'felis@eros.net',
+    'a@Incondimentum.org',
+    'nascetur@sitametmetus.org',
+    'risus@ProindolorNulla.edu',
+    'ante.ipsum.primis@gravidasagittis.com',
+    'Etiam@nisi.ca',
+    'Morbi.sit@pedemalesuada.net',
+    'risus.at@orci.co.uk',
+    'in.felis@Mauriseu.co.uk',
+    'Vivamus.nisi@risus.com',
+    'ut@ultricessit.co.uk',
+    'scelerisque.neque.sed@atlacus.net',
+    'et.malesuada@consequat.net',
+    'Donec.nibh.enim@pede.co.uk',
+    'purus.Nullam.scelerisque@ligula.net',
+    'mattis.velit@vel.net',
+    'cursus.et.eros@loremfringilla.ca',
+    'ullamcorper@tempusrisus.edu',
+    'gravida.sit@miAliquam.net',
+    'dolor@perconubia.edu',
+    'arcu@elit.ca',
+    'fringilla@Nulla.net',
+    'pretium.et@egestaslacinia.ca',
+    'risus@feugiatnonlobortis.co.uk',
+    'magna.Phasellus@Phasellus.net',
+    'feugiat@Aliquameratvolutpat.edu',
+    'dictum@Fuscefermentumfermentum.edu',
+    'Vestibulum.ante@sitametmassa.net',
+    'enim.Mauris.quis@Vivamusnibh.net',
+    'turpis@loremvehicula.com',
+    'velit@Pellentesquehabitantmorbi.org'
+);
+
+DELETE FROM wisdom.products WHERE name IN (
+    'Strong Joints Dog Supplement',
+    'Healthy Coat Dog Supplement',
+    'Probiotic Dog Treats',
+    'Small Hypoallergenic Pet Bowl',
+    'Large Hypoallergenic Pet Bowl',
+    'Cat Hairball Remedy Gel',
+    'Probiotic Cat Treats',
+    'Here Kitty Kitty Organic Catnip',
+    'Calm Cat Anxiety Relief Spray',
+    'Medicated Dog Shampoo',
+    'Strong Joints Cat Supplement',
+    'Healthy Coat Cat Supplement',
+    'Senior Dog Vitamin Chews',
+    'Kidney Support Liquid Dietary Supplement',
+    'Advance Pet Oral Care Toothbrush and Toothpaste'
+);
+
+DELETE FROM wisdom.services WHERE name IN (
+    'Dog Vaccination Package',
+    'Cat Vaccination Package',
+    'Rabies 1 or 3 year Vaccination',
+    'Parasite Treatment Package',
+    'Microchip Service',
+    'Basic Teeth Cleaning and Exam'
+);
+
+DELETE FROM wisdom.vendors WHERE name IN (
+    'Edgepulse',
+    'Quaxo',
+    'Talane',
+    'Chatterpoint',
+    'Jetwire',
+    'Babbleblab',
+    'DabZ',
+    'Kazio',
+    'Rooxo',
+    'Rhynyx',
+    'Jaloo',
+    'Photolist',
+    'Abata',
+    'Yakitri'
+);
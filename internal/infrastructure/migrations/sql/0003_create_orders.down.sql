@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wisdom.order_lines;
+DROP TABLE IF EXISTS wisdom.orders;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS wisdom.orders (
+      order_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      customer_id UUID NOT NULL REFERENCES wisdom.customers (customer_id) ON DELETE RESTRICT,
+      ordered_at TIMESTAMPTZ NOT NULL,
+      total NUMERIC (12,2)
+);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON wisdom.orders (customer_id);
+
+CREATE TABLE IF NOT EXISTS wisdom.order_lines (
+      order_line_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      order_id UUID NOT NULL REFERENCES wisdom.orders (order_id) ON DELETE CASCADE,
+      product_id UUID REFERENCES wisdom.products (product_id) ON DELETE RESTRICT,
+      service_id UUID REFERENCES wisdom.services (service_id) ON DELETE RESTRICT,
+      quantity BIGINT NOT NULL,
+      unit_price NUMERIC (12,2),
+      CHECK ((product_id IS NULL) <> (service_id IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_order_lines_order_id ON wisdom.order_lines (order_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS wisdom.pets (
+      pet_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      customer_id UUID NOT NULL REFERENCES wisdom.customers (customer_id) ON DELETE CASCADE,
+      name TEXT NOT NULL,
+      species TEXT NOT NULL,
+      breed TEXT,
+      birth_date DATE,
+      microchip_number TEXT UNIQUE
+);
+CREATE INDEX IF NOT EXISTS idx_pets_customer_id ON wisdom.pets (customer_id);
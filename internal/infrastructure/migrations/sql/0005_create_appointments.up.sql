@@ -0,0 +1,17 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS wisdom.appointments (
+      appointment_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      customer_id UUID NOT NULL REFERENCES wisdom.customers (customer_id) ON DELETE RESTRICT,
+      pet_id UUID REFERENCES wisdom.pets (pet_id) ON DELETE SET NULL,
+      service_id UUID NOT NULL REFERENCES wisdom.services (service_id) ON DELETE RESTRICT,
+      resource TEXT NOT NULL,
+      starts_at TIMESTAMPTZ NOT NULL,
+      duration_minutes BIGINT NOT NULL,
+      ends_at TIMESTAMPTZ NOT NULL,
+      -- Backstop for the advisory lock in AddAppointment: no two bookings of
+      -- the same resource may overlap.
+      EXCLUDE USING gist (resource WITH =, tstzrange(starts_at, ends_at) WITH &&)
+);
+CREATE INDEX IF NOT EXISTS idx_appointments_resource_start ON wisdom.appointments (resource, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_customer_id ON wisdom.appointments (customer_id);
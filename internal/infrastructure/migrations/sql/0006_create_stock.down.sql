@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS wisdom.stock_movements;
+
+ALTER TABLE wisdom.products
+      DROP COLUMN IF EXISTS reorder_threshold,
+      DROP COLUMN IF EXISTS quantity_on_hand;
@@ -0,0 +1,14 @@
+ALTER TABLE wisdom.products
+      ADD COLUMN IF NOT EXISTS quantity_on_hand BIGINT NOT NULL DEFAULT 0,
+      ADD COLUMN IF NOT EXISTS reorder_threshold BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS wisdom.stock_movements (
+      stock_movement_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      product_id UUID NOT NULL REFERENCES wisdom.products (product_id) ON DELETE CASCADE,
+      kind TEXT NOT NULL,
+      quantity BIGINT NOT NULL,
+      balance_after BIGINT NOT NULL,
+      reference TEXT,
+      created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON wisdom.stock_movements (product_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wisdom.purchase_order_lines;
+DROP TABLE IF EXISTS wisdom.purchase_orders;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS wisdom.purchase_orders (
+      purchase_order_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      vendor_id UUID NOT NULL REFERENCES wisdom.vendors (vendor_id) ON DELETE RESTRICT,
+      status TEXT NOT NULL,
+      created_at TIMESTAMPTZ,
+      updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_vendor_id ON wisdom.purchase_orders (vendor_id);
+
+CREATE TABLE IF NOT EXISTS wisdom.purchase_order_lines (
+      purchase_order_line_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      purchase_order_id UUID NOT NULL REFERENCES wisdom.purchase_orders (purchase_order_id) ON DELETE CASCADE,
+      product_id UUID NOT NULL REFERENCES wisdom.products (product_id) ON DELETE RESTRICT,
+      quantity_ordered BIGINT NOT NULL,
+      quantity_received BIGINT NOT NULL DEFAULT 0,
+      unit_cost NUMERIC (12,2)
+);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_purchase_order_id ON wisdom.purchase_order_lines (purchase_order_id);
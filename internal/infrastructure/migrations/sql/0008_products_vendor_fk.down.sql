@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS wisdom.idx_products_vendor_id;
+
+ALTER TABLE wisdom.products DROP CONSTRAINT IF EXISTS fk_products_vendor;
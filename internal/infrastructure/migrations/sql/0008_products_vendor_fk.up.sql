@@ -0,0 +1,8 @@
+-- Product.VendorID never produced a constraint, so products may point at
+-- vendors that no longer exist. This fails, naming the offending row, until
+-- those products are reassigned or removed.
+ALTER TABLE wisdom.products
+      ADD CONSTRAINT fk_products_vendor FOREIGN KEY (vendor_id)
+      REFERENCES wisdom.vendors (vendor_id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_products_vendor_id ON wisdom.products (vendor_id);
@@ -0,0 +1,18 @@
+-- Soft-deleted rows are purged first; they would otherwise reappear as live
+-- records and could collide with the restored unique constraints. This
+-- fails while orders still reference a soft-deleted row.
+DELETE FROM wisdom.products WHERE deleted_at IS NOT NULL;
+DELETE FROM wisdom.services WHERE deleted_at IS NOT NULL;
+DELETE FROM wisdom.customers WHERE deleted_at IS NOT NULL;
+DELETE FROM wisdom.vendors WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS wisdom.uq_services_name;
+ALTER TABLE wisdom.services ADD CONSTRAINT services_name_key UNIQUE (name);
+
+DROP INDEX IF EXISTS wisdom.uq_products_name;
+ALTER TABLE wisdom.products ADD CONSTRAINT products_name_key UNIQUE (name);
+
+ALTER TABLE wisdom.services DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE wisdom.products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE wisdom.vendors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE wisdom.customers DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,20 @@
+ALTER TABLE wisdom.customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE wisdom.vendors ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE wisdom.products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE wisdom.services ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_customers_deleted_at ON wisdom.customers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_vendors_deleted_at ON wisdom.vendors (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON wisdom.products (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_services_deleted_at ON wisdom.services (deleted_at);
+
+-- Names only need to be unique among live rows, so a deleted product or
+-- service does not block re-creating one with the same name. Databases
+-- created by AutoMigrate named the constraint uni_<table>_name.
+ALTER TABLE wisdom.products DROP CONSTRAINT IF EXISTS products_name_key;
+ALTER TABLE wisdom.products DROP CONSTRAINT IF EXISTS uni_products_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_products_name ON wisdom.products (name) WHERE deleted_at IS NULL;
+
+ALTER TABLE wisdom.services DROP CONSTRAINT IF EXISTS services_name_key;
+ALTER TABLE wisdom.services DROP CONSTRAINT IF EXISTS uni_services_name;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_services_name ON wisdom.services (name) WHERE deleted_at IS NULL;
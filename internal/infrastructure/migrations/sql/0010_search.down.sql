@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS wisdom.idx_vendors_name_trgm;
+DROP INDEX IF EXISTS wisdom.idx_vendors_search_vector;
+ALTER TABLE wisdom.vendors DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS wisdom.idx_services_name_trgm;
+DROP INDEX IF EXISTS wisdom.idx_services_search_vector;
+ALTER TABLE wisdom.services DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS wisdom.idx_products_name_trgm;
+DROP INDEX IF EXISTS wisdom.idx_products_search_vector;
+ALTER TABLE wisdom.products DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed, as other schemas may use it too
@@ -0,0 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full-text search reads the stored tsvector; the trigram indexes serve the
+-- similarity fallback that catches typos.
+ALTER TABLE wisdom.products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+      GENERATED ALWAYS AS (to_tsvector('english', coalesce(name, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON wisdom.products USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON wisdom.products USING gin (name gin_trgm_ops);
+
+ALTER TABLE wisdom.services ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+      GENERATED ALWAYS AS (to_tsvector('english', coalesce(name, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_services_search_vector ON wisdom.services USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_services_name_trgm ON wisdom.services USING gin (name gin_trgm_ops);
+
+ALTER TABLE wisdom.vendors ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+      GENERATED ALWAYS AS (to_tsvector('english', coalesce(name, '') || ' ' || coalesce(contact, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_vendors_search_vector ON wisdom.vendors USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_vendors_name_trgm ON wisdom.vendors USING gin (name gin_trgm_ops);
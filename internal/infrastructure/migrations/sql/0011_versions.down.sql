@@ -0,0 +1,8 @@
+ALTER TABLE wisdom.purchase_orders DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.appointments DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.orders DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.services DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.products DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.vendors DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.pets DROP COLUMN IF EXISTS version;
+ALTER TABLE wisdom.customers DROP COLUMN IF EXISTS version;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wisdom.role_assignments;
+DROP TABLE IF EXISTS wisdom.roles;
@@ -0,0 +1,25 @@
+-- Roles grant permissions such as products:write; subjects, the sub claim
+-- of a caller's token, hold roles through assignments.
+CREATE TABLE IF NOT EXISTS wisdom.roles (
+      role_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      name TEXT NOT NULL UNIQUE,
+      description TEXT,
+      permissions TEXT[] NOT NULL DEFAULT '{}',
+      version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS wisdom.role_assignments (
+      subject TEXT NOT NULL,
+      role_id UUID NOT NULL REFERENCES wisdom.roles (role_id) ON DELETE RESTRICT,
+      created_at TIMESTAMPTZ,
+      PRIMARY KEY (subject, role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_role_assignments_role_id ON wisdom.role_assignments (role_id);
+
+INSERT INTO wisdom.roles (name, description, permissions) VALUES
+      ('admin', 'Everything, including managing roles', '{*:*}'),
+      ('front-desk', 'Manages customers, their pets, orders and appointments',
+       '{customers:read,customers:write,orders:read,orders:write,appointments:read,appointments:write,appointments:delete,products:read,services:read}'),
+      ('inventory', 'Manages the catalogue, stock and purchasing',
+       '{products:read,products:write,products:delete,services:read,services:write,vendors:read,vendors:write,purchase-orders:read,purchase-orders:write}')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS wisdom.api_keys;
@@ -0,0 +1,15 @@
+-- API keys let scripts and jobs call the API without an interactive login.
+-- Only a SHA-256 hash of each key is kept; the prefix, which is part of the
+-- key itself, finds the row to compare it with.
+CREATE TABLE IF NOT EXISTS wisdom.api_keys (
+      api_key_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      name TEXT NOT NULL,
+      prefix TEXT NOT NULL UNIQUE,
+      hash BYTEA NOT NULL,
+      permissions TEXT[] NOT NULL DEFAULT '{}',
+      expires_at TIMESTAMPTZ,
+      last_used_at TIMESTAMPTZ,
+      revoked_at TIMESTAMPTZ,
+      created_by TEXT,
+      created_at TIMESTAMPTZ
+);
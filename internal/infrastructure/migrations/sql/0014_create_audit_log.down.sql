@@ -0,0 +1 @@
+DROP TABLE IF EXISTS wisdom.audit_log;
@@ -0,0 +1,13 @@
+-- Every create, update and delete made through the API is recorded with who
+-- made it and the old and new values of the fields it changed.
+CREATE TABLE IF NOT EXISTS wisdom.audit_log (
+      audit_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      actor TEXT,
+      action TEXT NOT NULL,
+      entity TEXT NOT NULL,
+      entity_id TEXT NOT NULL,
+      changes JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON wisdom.audit_log (entity, entity_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON wisdom.audit_log (occurred_at);
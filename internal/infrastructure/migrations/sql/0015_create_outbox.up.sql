@@ -0,0 +1,16 @@
+-- Domain events are written here in the transaction of the change that
+-- raised them, and relayed to the publisher in sequence order afterwards.
+CREATE TABLE IF NOT EXISTS wisdom.outbox (
+      event_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+      sequence BIGSERIAL NOT NULL UNIQUE,
+      type TEXT NOT NULL,
+      aggregate_type TEXT NOT NULL,
+      aggregate_id TEXT NOT NULL,
+      actor TEXT,
+      occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      payload JSONB NOT NULL,
+      published_at TIMESTAMPTZ,
+      attempts INT NOT NULL DEFAULT 0,
+      last_error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON wisdom.outbox (sequence) WHERE published_at IS NULL;
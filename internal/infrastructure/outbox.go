@@ -0,0 +1,118 @@
+package database
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// aggregates names the audited entities that raise domain events, as event
+// types start with them.
+var aggregates = map[string]string{
+	"customer":       "Customer",
+	"pet":            "Pet",
+	"product":        "Product",
+	"service":        "Service",
+	"vendor":         "Vendor",
+	"purchase_order": "PurchaseOrder",
+	"order":          "Order",
+	"appointment":    "Appointment",
+}
+
+// fieldEvents are the events an aggregate raises, besides its Updated event,
+// when one of its fields changes.
+var fieldEvents = map[string]map[string]string{
+	"product": {
+		"price":            "ProductPriceChanged",
+		"quantity_on_hand": "ProductStockChanged",
+	},
+	"purchase_order": {
+		"status": "PurchaseOrderStatusChanged",
+	},
+}
+
+// domainEvents are the events a change to one record of entity raises:
+// Created, Updated, Deleted, Restored or Purged, the last for records
+// removed for good after being deleted, followed by its field events.
+// before and after are the record either side of the change, either of
+// which may be missing.
+func domainEvents(entity string, action string, changes map[string]models.AuditChange, before, after reflect.Value) ([]models.Event, error) {
+	aggregate, ok := aggregates[entity]
+	if !ok || len(changes) == 0 {
+		return nil, nil
+	}
+
+	var types []string
+	fields := map[string]models.AuditChange{}
+	deletedAt, softDeletable := changes["deleted_at"]
+	switch {
+	case action == models.AuditCreate:
+		types = append(types, aggregate+"Created")
+	case !after.IsValid() && before.IsValid() && isDeleted(before):
+		types = append(types, aggregate+"Purged")
+	case !after.IsValid():
+		types = append(types, aggregate+"Deleted")
+	case softDeletable && isNull(deletedAt.New):
+		types = append(types, aggregate+"Restored")
+	case softDeletable:
+		types = append(types, aggregate+"Deleted")
+	default:
+		types = append(types, aggregate+"Updated")
+		for field, change := range changes {
+			if _, ok := fieldEvents[entity][field]; ok {
+				fields[field] = change
+			}
+		}
+	}
+
+	data := after
+	if !data.IsValid() {
+		data = before
+	}
+
+	var events []models.Event
+	raise := func(kind string, changes map[string]models.AuditChange) error {
+		payload, err := json.Marshal(models.EventPayload{Changes: changes, Data: data.Interface()})
+		if err != nil {
+			return err
+		}
+		events = append(events, models.Event{Type: kind, AggregateType: entity, Payload: payload})
+		return nil
+	}
+	for _, kind := range types {
+		var eventChanges map[string]models.AuditChange
+		if kind == aggregate+"Updated" {
+			eventChanges = changes
+		}
+		if err := raise(kind, eventChanges); err != nil {
+			return nil, err
+		}
+	}
+	// Field events are raised in a fixed order so replays match
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		change := map[string]models.AuditChange{field: fields[field]}
+		if err := raise(fieldEvents[entity][field], change); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// isDeleted reports whether row, a model, was soft deleted.
+func isDeleted(row reflect.Value) bool {
+	deletedAt := row.FieldByName("DeletedAt")
+	if !deletedAt.IsValid() {
+		return false
+	}
+	valid := deletedAt.FieldByName("Valid")
+	return valid.IsValid() && valid.Bool()
+}
+
+// isNull reports whether an audited value is the JSON null.
+func isNull(value interface{}) bool {
+	raw, ok := value.(json.RawMessage)
+	return value == nil || ok && string(raw) == "null"
+}
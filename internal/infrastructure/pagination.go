@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// schemas caches the parsed models used to read cursor positions.
+var schemas sync.Map
+
+// cursor is the position carried by an opaque page cursor: the values of the
+// sort keys of the row at the page's edge. Sort is the order the cursor was
+// issued for, so it cannot be replayed against another order.
+type cursor struct {
+	Values   []string `json:"v"`
+	Sort     string   `json:"s"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return cursor{}, &dberrors.InvalidQueryError{Parameter: "cursor", Message: "is not a valid cursor"}
+	}
+	return c, nil
+}
+
+// pageBounds reads the page index and size of request, falling back to the
+// first page of 10 and allowing at most 100 rows.
+func pageBounds(request models.PageRequest) (int, int) {
+	// Default values for page and pageSize
+	page, err := strconv.Atoi(request.PageIndex)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pSize, err := strconv.Atoi(request.PageSize)
+	if err != nil || pSize < 1 {
+		pSize = 10
+	} else if pSize > 100 {
+		pSize = 100
+	}
+
+	return page, pSize
+}
+
+// paginate filters and sorts query as described by l and request, then loads
+// one page of it. preloads are applied to the page query only, not to the
+// total count.
+func paginate[T any](query *gorm.DB, request models.PageRequest, l listing, preloads ...string) (*models.Page[T], error) {
+	page, pSize := pageBounds(request)
+
+	sort := request.Sort
+	if sort == "" {
+		sort = l.Sort
+	}
+	keys, err := l.sortKeys(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err = l.filter(query, request.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *cursor
+	if request.Cursor != "" {
+		c, err := decodeCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sort || len(c.Values) != len(keys) {
+			return nil, &dberrors.InvalidQueryError{Parameter: "cursor", Message: "was issued for a different sort"}
+		}
+		from = &c
+	}
+
+	result := &models.Page[T]{Items: []T{}}
+
+	if request.IncludeTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Model(new(T)).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	// Walking backwards reads the rows before the cursor in reverse order
+	backward := from != nil && from.Backward
+
+	pageQuery := query.Session(&gorm.Session{})
+	for _, preload := range preloads {
+		pageQuery = pageQuery.Preload(preload)
+	}
+	if from != nil {
+		condition, args := seek(keys, from.Values, backward)
+		pageQuery = pageQuery.Where(condition, args...)
+	} else {
+		pageQuery = pageQuery.Offset((page - 1) * pSize)
+	}
+
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "ASC"
+		if key.Desc != backward {
+			direction = "DESC"
+		}
+		order[i] = key.expression() + " " + direction
+	}
+
+	// Read one row more than the page holds to learn whether another page follows
+	var items []T
+	err = pageQuery.
+		Order(strings.Join(order, ", ")).
+		Limit(pSize + 1).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > pSize
+	if more {
+		items = items[:pSize]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	if len(items) == 0 {
+		return result, nil
+	}
+	result.Items = items
+
+	// There is a next page when we read past the end going forward or came
+	// backwards from it; likewise for the previous page.
+	if more || backward {
+		values, err := position(query, keys, items[len(items)-1])
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = encodeCursor(cursor{Values: values, Sort: sort})
+	}
+	if (backward && more) || (!backward && (from != nil || page > 1)) {
+		values, err := position(query, keys, items[0])
+		if err != nil {
+			return nil, err
+		}
+		result.PrevCursor = encodeCursor(cursor{Values: values, Sort: sort, Backward: true})
+	}
+
+	return result, nil
+}
+
+// seek builds the condition selecting the rows that come after values in the
+// order of keys, or before them when walking backward.
+func seek(keys []sortKey, values []string, backward bool) (string, []interface{}) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j, previous := range keys[:i] {
+			parts = append(parts, fmt.Sprintf("%s = CAST(? AS %s)", previous.expression(), previous.Type))
+			args = append(args, values[j])
+		}
+
+		comparison := ">"
+		if key.Desc != backward {
+			comparison = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s CAST(? AS %s)", key.expression(), comparison, key.Type))
+		args = append(args, values[i])
+
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// position reads the sort key values of item for a cursor.
+func position[T any](db *gorm.DB, keys []sortKey, item T) ([]string, error) {
+	s, err := schema.Parse(new(T), &schemas, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		f := s.LookUpField(key.Column)
+		if f == nil {
+			return nil, fmt.Errorf("%s has no column %s", s.Name, key.Column)
+		}
+
+		value, _ := f.ValueOf(context.Background(), reflect.ValueOf(&item))
+		switch v := value.(type) {
+		case time.Time:
+			values[i] = v.UTC().Format(time.RFC3339Nano)
+		case float64:
+			values[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
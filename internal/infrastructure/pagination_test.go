@@ -0,0 +1,186 @@
+package database
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// Cursors survive the trip to the client and back unchanged.
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []cursor{
+		{Values: []string{"Kibble", "3f1c1a7e-5b1f-4c59-9d3a-0c1e8f1d2b4a"}, Sort: "name"},
+		{Values: []string{"12.5", "Kibble", "3f1c1a7e-5b1f-4c59-9d3a-0c1e8f1d2b4a"}, Sort: "-price,name", Backward: true},
+		{Values: []string{"", "a,b", `"quoted"`, "ü"}, Sort: "first_name,last_name"},
+	}
+
+	for _, test := range tests {
+		encoded := encodeCursor(test)
+		decoded, err := decodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q) = %v", encoded, err)
+		}
+		if !reflect.DeepEqual(decoded, test) {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", test, decoded)
+		}
+	}
+}
+
+// Cursors that were not issued for the listing and sort asked for are
+// refused before any rows are read.
+func TestPaginateRejectsCursor(t *testing.T) {
+	raw := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	tests := []struct {
+		name    string
+		sort    string
+		cursor  string
+		message string
+	}{
+		{name: "not base64", cursor: "!!!", message: "is not a valid cursor"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"v":["a","b"],"s":"name"}`)), message: "is not a valid cursor"},
+		{name: "not JSON", cursor: raw("name=Kibble"), message: "is not a valid cursor"},
+		{name: "wrong shape", cursor: raw(`{"v":"Kibble","s":"name"}`), message: "is not a valid cursor"},
+		{name: "other sort", cursor: encodeCursor(cursor{Values: []string{"1", "id"}, Sort: "-price"}), message: "different sort"},
+		{name: "sort changed", sort: "-price", cursor: encodeCursor(cursor{Values: []string{"Kibble", "id"}, Sort: "name"}), message: "different sort"},
+		{name: "values dropped", cursor: encodeCursor(cursor{Values: []string{"Kibble"}, Sort: "name"}), message: "different sort"},
+		{name: "values added", cursor: encodeCursor(cursor{Values: []string{"Kibble", "id", "x"}, Sort: "name"}), message: "different sort"},
+	}
+
+	db := dryRunDB(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := models.PageRequest{Sort: test.sort, Cursor: test.cursor}
+			_, err := paginate[models.Product](db, request, productListing)
+			checkInvalidQuery(t, err, "cursor", test.message)
+		})
+	}
+}
+
+// seek selects the rows after a cursor's values in the listing's order, or
+// before them walking backward, comparing one key more at each step.
+func TestSeek(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		values   []string
+		backward bool
+		want     string
+	}{
+		{
+			name:   "ascending",
+			sort:   "name",
+			values: []string{"Kibble", "id"},
+			want:   "((name > CAST(? AS text)) OR (name = CAST(? AS text) AND product_id > CAST(? AS uuid)))",
+		},
+		{
+			name:     "ascending backward",
+			sort:     "name",
+			values:   []string{"Kibble", "id"},
+			backward: true,
+			want:     "((name < CAST(? AS text)) OR (name = CAST(? AS text) AND product_id < CAST(? AS uuid)))",
+		},
+		{
+			name:   "mixed",
+			sort:   "-price,name",
+			values: []string{"12.5", "Kibble", "id"},
+			want: "((COALESCE(price, CAST(0 AS numeric)) < CAST(? AS numeric)) OR " +
+				"(COALESCE(price, CAST(0 AS numeric)) = CAST(? AS numeric) AND name > CAST(? AS text)) OR " +
+				"(COALESCE(price, CAST(0 AS numeric)) = CAST(? AS numeric) AND name = CAST(? AS text) AND product_id > CAST(? AS uuid)))",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := productListing.sortKeys(test.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			condition, args := seek(keys, test.values, test.backward)
+			if condition != test.want {
+				t.Errorf("seek condition = %s, want %s", condition, test.want)
+			}
+
+			// Each step repeats the values of the keys before it
+			var want []interface{}
+			for i := range test.values {
+				for _, value := range test.values[:i+1] {
+					want = append(want, value)
+				}
+			}
+			if !reflect.DeepEqual(args, want) {
+				t.Errorf("seek args = %q, want %q", args, want)
+			}
+		})
+	}
+}
+
+// A cursor records every sort key of the row it points at, the ID last, in
+// a form the database casts back to the column's type.
+func TestPosition(t *testing.T) {
+	id := uuid.MustParse("3f1c1a7e-5b1f-4c59-9d3a-0c1e8f1d2b4a")
+	startsAt := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	db := dryRunDB(t)
+	tests := []struct {
+		name     string
+		position func() ([]string, error)
+		want     []string
+	}{
+		{
+			name: "text",
+			position: func() ([]string, error) {
+				return positionIn(db, productListing, "name", models.Product{ProductID: id, Name: "Kibble"})
+			},
+			want: []string{"Kibble", id.String()},
+		},
+		{
+			name: "number",
+			position: func() ([]string, error) {
+				return positionIn(db, productListing, "-price,name", models.Product{ProductID: id, Name: "Kibble", Price: 12.5})
+			},
+			want: []string{"12.5", "Kibble", id.String()},
+		},
+		{
+			name: "time",
+			position: func() ([]string, error) {
+				return positionIn(db, appointmentListing, "starts_at", models.Appointment{AppointmentID: id, StartsAt: startsAt})
+			},
+			want: []string{"2026-10-17T07:30:00Z", id.String()},
+		},
+		{
+			name: "customers by default",
+			position: func() ([]string, error) {
+				return positionIn(db, customerListing, customerListing.Sort, models.Customer{CustomerID: id, FirstName: "Ada", LastName: "Lovelace"})
+			},
+			want: []string{"Ada", "Lovelace", id.String()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.position()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("position = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// positionIn reads the cursor values of item in l sorted by sort.
+func positionIn[T any](db *gorm.DB, l listing, sort string, item T) ([]string, error) {
+	keys, err := l.sortKeys(sort)
+	if err != nil {
+		return nil, err
+	}
+	return position(db, keys, item)
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// patchRecord does the work of a partial update on tx. It locks the live T
+// with the given ID, checks it is still at version as lockVersion does and
+// hands it to apply, which returns the record as it should be. Only the
+// columns that differ are written, together with the version bump; the
+// primary key, version, deleted_at and readOnly columns cannot change. It
+// returns the stored record and the columns written, none for a no-op patch.
+// tx may carry conditions the record must also meet, such as its owner.
+func patchRecord[T any](tx *gorm.DB, entity string, id uuid.UUID, version int, readOnly []string, apply func(current *T) (*T, error)) (*T, []string, error) {
+	next, err := lockVersion[T](tx, entity, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := new(T)
+	if err := tx.Take(current, id).Error; err != nil {
+		return nil, nil, err
+	}
+
+	patched, err := apply(current)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(patched); err != nil {
+		return nil, nil, err
+	}
+
+	ctx := tx.Statement.Context
+	before, after := reflect.ValueOf(current).Elem(), reflect.ValueOf(patched).Elem()
+
+	var changed []string
+	var fixed dberrors.ValidationErrors
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		was, _ := field.ValueOf(ctx, before)
+		is, _ := field.ValueOf(ctx, after)
+		if reflect.DeepEqual(was, is) {
+			continue
+		}
+
+		if field.PrimaryKey || field.DBName == "version" || field.DBName == "deleted_at" || slices.Contains(readOnly, field.DBName) {
+			fixed = append(fixed, dberrors.ValidationError{Field: jsonName(field), Message: "cannot be changed"})
+			continue
+		}
+		changed = append(changed, field.DBName)
+	}
+	if len(fixed) > 0 {
+		return nil, nil, fixed
+	}
+	if err := models.Validate(patched); err != nil {
+		return nil, nil, err
+	}
+	if len(changed) == 0 {
+		return current, nil, nil
+	}
+
+	if err := stmt.Schema.LookUpField("version").Set(ctx, after, next); err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Model(patched).
+		Clauses(clause.Returning{}).
+		Select(append(changed, "version")).
+		Updates(patched).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return patched, changed, nil
+}
+
+// jsonName is the name clients know field by.
+func jsonName(field *schema.Field) string {
+	if name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.DBName
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// searchable is an entity with a search_vector column. Column is the text
+// the trigram fallback matches and highlights are drawn from.
+type searchable struct {
+	Column string
+	ID     string
+}
+
+var (
+	productSearch = searchable{Column: "name", ID: "product_id"}
+	serviceSearch = searchable{Column: "name", ID: "service_id"}
+	vendorSearch  = searchable{Column: "name", ID: "vendor_id"}
+)
+
+// searchHit is the rank and highlight of one matching row.
+type searchHit struct {
+	ID        uuid.UUID
+	Rank      float64
+	Highlight string
+}
+
+// search ranks the rows of query matching term. Full-text matches on
+// search_vector rank highest; rows whose text is within trigram word
+// similarity of the term are included too, so typos still find something.
+// Results can be narrowed by the listing's filters but are always ordered by
+// rank and paged by page index.
+func search[T any](query *gorm.DB, term string, request models.PageRequest, l listing, s searchable) (*models.Page[models.SearchHit[T]], error) {
+	if strings.TrimSpace(term) == "" {
+		return nil, &dberrors.InvalidQueryError{Parameter: "q", Message: "is required"}
+	}
+	if request.Sort != "" {
+		return nil, &dberrors.InvalidQueryError{Parameter: "sort", Message: "search results are always ordered by rank"}
+	}
+	if request.Cursor != "" {
+		return nil, &dberrors.InvalidQueryError{Parameter: "cursor", Message: "search results are paged by pageindex"}
+	}
+
+	page, pSize := pageBounds(request)
+
+	query, err := l.filter(query, request.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// websearch_to_tsquery accepts free text, so no user input can break the query
+	tsquery := "websearch_to_tsquery('english', @term)"
+	term = strings.TrimSpace(term)
+	query = query.Model(new(T)).Where(
+		fmt.Sprintf("(search_vector @@ %s OR @term <%% %s)", tsquery, s.Column),
+		sql.Named("term", term),
+	)
+
+	result := &models.Page[models.SearchHit[T]]{Items: []models.SearchHit[T]{}}
+
+	if request.IncludeTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	var hits []searchHit
+	err = query.Session(&gorm.Session{}).
+		Select(fmt.Sprintf(
+			"%s AS id, ts_rank(search_vector, %s) + word_similarity(@term, coalesce(%s, '')) AS rank, "+
+				"ts_headline('english', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight",
+			s.ID, tsquery, s.Column, escapedHTML("coalesce("+s.Column+", '')"), tsquery,
+		), sql.Named("term", term)).
+		Order("rank DESC, " + s.ID).
+		Limit(pSize).
+		Offset((page - 1) * pSize).
+		Find(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return result, nil
+	}
+
+	// Load the matched rows and return them in rank order
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var items []T
+	if err := query.Session(&gorm.Session{NewDB: true}).Where(fmt.Sprintf("%s IN ?", s.ID), ids).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]T, len(items))
+	idKey := []sortKey{{field: field{Column: s.ID, Type: "uuid"}}}
+	for _, item := range items {
+		values, err := position(query, idKey, item)
+		if err != nil {
+			return nil, err
+		}
+		byID[values[0]] = item
+	}
+
+	for _, hit := range hits {
+		if item, ok := byID[hit.ID.String()]; ok {
+			result.Items = append(result.Items, models.SearchHit[T]{Item: item, Rank: hit.Rank, Highlight: hit.Highlight})
+		}
+	}
+
+	return result, nil
+}
+
+// htmlEscapes are the characters escapedHTML replaces, & first so the
+// entities it writes are not escaped again.
+var htmlEscapes = [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}}
+
+// escapedHTML is a SQL expression for the text of expr escaped as HTML. The
+// text highlights are drawn from is escaped before <mark> tags are added, so
+// a name holding markup comes back as text clients can render safely.
+func escapedHTML(expr string) string {
+	for _, escape := range htmlEscapes {
+		expr = fmt.Sprintf("replace(%s, '%s', '%s')", expr, strings.ReplaceAll(escape[0], "'", "''"), escape[1])
+	}
+	return expr
+}
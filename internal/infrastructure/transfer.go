@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many records an export loads at a time.
+const exportBatchSize = 500
+
+// export hands every record of query matching filters to each, loading them
+// in batches so a large catalogue is never held in memory at once.
+func export[T any](query *gorm.DB, filters []string, l listing, each func(item *T) error) error {
+	query, err := l.filter(query, filters)
+	if err != nil {
+		return err
+	}
+
+	var batch []T
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := each(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result.Error
+}
+
+// importer applies import rows of an entity: rows without an ID create a
+// record and rows with one update the columns they give.
+type importer[T any] struct {
+	batchWriter[T]
+	ID    func(item *T) uuid.UUID
+	Patch func(tx *gorm.DB, id uuid.UUID, version int, apply func(current *T) (*T, error)) (*T, error)
+	// CreateOnly columns are read for new records and left alone by updates
+	CreateOnly []string
+	// Resolve, when set, fills in the references a row names on the import's
+	// transaction before the row is validated.
+	Resolve func(tx *gorm.DB, row *models.ImportRow[T]) error
+}
+
+// runImport applies rows in one transaction like a batch. Rows that could
+// not be read fail like any other write, so they roll back an atomic import.
+func runImport[T any](db *gorm.DB, rows []models.ImportRow[T], options models.ImportOptions, imp importer[T]) ([]models.BatchResult[T], error) {
+	if err := models.Validate(options); err != nil {
+		return nil, err
+	}
+	if options.Mode == "" {
+		options.Mode = models.BatchAtomic
+	}
+
+	if len(rows) == 0 {
+		return nil, &dberrors.ValidationError{Field: "file", Message: "has no rows"}
+	}
+	if len(rows) > models.MaxImportRows {
+		return nil, &dberrors.ValidationError{Field: "file", Message: fmt.Sprintf("may hold at most %d rows", models.MaxImportRows)}
+	}
+
+	writes := make([]write[T], len(rows))
+	for i := range rows {
+		row := &rows[i]
+		id := imp.ID(&row.Item)
+		writes[i] = write[T]{Operation: models.BatchCreate, Index: row.Line}
+		if id != uuid.Nil {
+			writes[i].Operation = models.BatchUpdate
+			writes[i].ID = id.String()
+		}
+
+		writes[i].Run = func(tx *gorm.DB) (*T, error) {
+			if row.Err != nil {
+				return nil, row.Err
+			}
+			if imp.Resolve != nil {
+				if err := imp.Resolve(tx, row); err != nil {
+					return nil, err
+				}
+			}
+			if id == uuid.Nil {
+				return validated(&row.Item, imp.Create)(tx)
+			}
+			return imp.update(tx, id, row)
+		}
+	}
+
+	return runWrites(db, options.Mode, options.DryRun, writes)
+}
+
+// update writes the columns row gives onto the stored record with its ID.
+// The row's version, when given, must match the stored one as for a patch.
+func (imp importer[T]) update(tx *gorm.DB, id uuid.UUID, row *models.ImportRow[T]) (*T, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(&row.Item); err != nil {
+		return nil, err
+	}
+	ctx := tx.Statement.Context
+	item := reflect.ValueOf(&row.Item).Elem()
+
+	version := 0
+	if field := stmt.Schema.LookUpField("version"); field != nil {
+		value, _ := field.ValueOf(ctx, item)
+		version, _ = value.(int)
+	}
+
+	return imp.Patch(tx, id, version, func(current *T) (*T, error) {
+		patched := new(T)
+		*patched = *current
+		target := reflect.ValueOf(patched).Elem()
+		for _, name := range row.Columns {
+			field := stmt.Schema.LookUpField(name)
+			if field == nil || field.PrimaryKey || name == "version" || slices.Contains(imp.CreateOnly, name) {
+				continue
+			}
+			value, _ := field.ValueOf(ctx, item)
+			if err := field.Set(ctx, target, value); err != nil {
+				return nil, err
+			}
+		}
+		return patched, nil
+	})
+}
+
+// ExportProducts hands every live product matching filters to each.
+func (c Client) ExportProducts(ctx context.Context, filters []string, each func(product *models.Product) error) error {
+	return export(c.DB.WithContext(ctx), filters, productListing, each)
+}
+
+func (c Client) ExportCustomers(ctx context.Context, filters []string, each func(customer *models.Customer) error) error {
+	return export(c.DB.WithContext(ctx), filters, customerListing, each)
+}
+
+func (c Client) ExportServices(ctx context.Context, filters []string, each func(service *models.Service) error) error {
+	return export(c.DB.WithContext(ctx), filters, serviceListing, each)
+}
+
+func (c Client) ExportVendors(ctx context.Context, filters []string, each func(vendor *models.Vendor) error) error {
+	return export(c.DB.WithContext(ctx), filters, vendorListing, each)
+}
+
+// ImportProducts creates and updates products from rows. A row may name its
+// vendor instead of giving vendor_id; the name must match exactly one live
+// vendor.
+func (c Client) ImportProducts(ctx context.Context, rows []models.ImportRow[models.Product], options models.ImportOptions) ([]models.BatchResult[models.Product], error) {
+	return runImport(c.DB.WithContext(ctx), rows, options, importer[models.Product]{
+		batchWriter: productWriter,
+		ID:          func(p *models.Product) uuid.UUID { return p.ProductID },
+		Patch:       patchProduct,
+		CreateOnly:  []string{"quantity_on_hand"},
+		Resolve:     vendorsByName(),
+	})
+}
+
+func (c Client) ImportCustomers(ctx context.Context, rows []models.ImportRow[models.Customer], options models.ImportOptions) ([]models.BatchResult[models.Customer], error) {
+	return runImport(c.DB.WithContext(ctx), rows, options, importer[models.Customer]{
+		batchWriter: customerWriter,
+		ID:          func(c *models.Customer) uuid.UUID { return c.CustomerID },
+		Patch:       patchCustomer,
+	})
+}
+
+func (c Client) ImportServices(ctx context.Context, rows []models.ImportRow[models.Service], options models.ImportOptions) ([]models.BatchResult[models.Service], error) {
+	return runImport(c.DB.WithContext(ctx), rows, options, importer[models.Service]{
+		batchWriter: serviceWriter,
+		ID:          func(s *models.Service) uuid.UUID { return s.ServiceID },
+		Patch:       patchService,
+	})
+}
+
+func (c Client) ImportVendors(ctx context.Context, rows []models.ImportRow[models.Vendor], options models.ImportOptions) ([]models.BatchResult[models.Vendor], error) {
+	return runImport(c.DB.WithContext(ctx), rows, options, importer[models.Vendor]{
+		batchWriter: vendorWriter,
+		ID:          func(v *models.Vendor) uuid.UUID { return v.VendorID },
+		Patch:       patchVendor,
+	})
+}
+
+// vendorsByName resolves the vendor column of product rows, remembering each
+// name it has looked up for the rest of the import.
+func vendorsByName() func(tx *gorm.DB, row *models.ImportRow[models.Product]) error {
+	known := make(map[string]uuid.UUID)
+
+	return func(tx *gorm.DB, row *models.ImportRow[models.Product]) error {
+		name, ok := row.References["vendor"]
+		if !ok {
+			return nil
+		}
+		if row.Item.VendorID != uuid.Nil {
+			return &dberrors.ValidationError{Field: "vendor", Message: "cannot be given together with vendor_id"}
+		}
+
+		// The resolved vendor is written like a vendor_id column
+		row.Columns = append(row.Columns, "vendor_id")
+		if id, ok := known[name]; ok {
+			row.Item.VendorID = id
+			return nil
+		}
+
+		var ids []uuid.UUID
+		if err := tx.Model(&models.Vendor{}).Where("name = ?", name).Limit(2).Pluck("vendor_id", &ids).Error; err != nil {
+			return err
+		}
+		switch len(ids) {
+		case 0:
+			return &dberrors.ValidationError{Field: "vendor", Message: fmt.Sprintf("no vendor is named %q", name)}
+		case 1:
+			known[name] = ids[0]
+			row.Item.VendorID = ids[0]
+			return nil
+		default:
+			return &dberrors.ValidationError{Field: "vendor", Message: fmt.Sprintf("more than one vendor is named %q", name)}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockVersion locks the live record of T with the given ID until tx ends
+// and checks it is still at version; zero skips the check, as If-Match: *
+// does. It returns the version the record moves to when written, so a write
+// can never turn into an insert of a record that does not exist.
+func lockVersion[T any](tx *gorm.DB, entity string, id uuid.UUID, version int) (int, error) {
+	var current struct{ Version int }
+	err := tx.Model(new(T)).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("version").
+		Take(&current, id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &dberrors.NotFoundError{Entity: entity, ID: id}
+		}
+		return 0, err
+	}
+	if version != 0 && current.Version != version {
+		return 0, &dberrors.PreconditionFailedError{Entity: entity, ID: id, Version: current.Version}
+	}
+
+	return current.Version + 1, nil
+}
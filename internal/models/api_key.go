@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKey lets a machine client call the API with the permissions it was
+// issued with. The key itself is shown once, when it is issued; only its
+// hash is stored.
+type APIKey struct {
+	APIKeyID    uuid.UUID      `json:"api_key_id" gorm:"column:api_key_id;type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string         `json:"name" gorm:"not null" validate:"required"`
+	Prefix      string         `json:"prefix" gorm:"not null;unique"`
+	Hash        []byte         `json:"-" gorm:"not null"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[];not null" validate:"required,min=1,dive,permission" swaggertype:"array,string"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time     `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time     `json:"revoked_at,omitempty"`
+	CreatedBy   string         `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TableName sets the table name for APIKey
+func (APIKey) TableName() string {
+	return "wisdom.api_keys"
+}
+
+// Active reports whether the key may still be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAppointmentResource is used when a booking does not name the room,
+// vet or table it needs.
+const DefaultAppointmentResource = "clinic"
+
+type Appointment struct {
+	AppointmentID   uuid.UUID  `json:"appointment_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID      uuid.UUID  `json:"customer_id" gorm:"type:uuid;not null;index"`
+	Customer        *Customer  `json:"-" gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:RESTRICT"`
+	PetID           *uuid.UUID `json:"pet_id,omitempty" gorm:"type:uuid"`
+	Pet             *Pet       `json:"-" gorm:"foreignKey:PetID;references:PetID;constraint:OnDelete:SET NULL"`
+	ServiceID       uuid.UUID  `json:"service_id" gorm:"type:uuid;not null"`
+	Service         *Service   `json:"-" gorm:"foreignKey:ServiceID;references:ServiceID;constraint:OnDelete:RESTRICT"`
+	Resource        string     `json:"resource" gorm:"not null;index:idx_appointments_resource_start"`
+	StartsAt        time.Time  `json:"starts_at" gorm:"not null;index:idx_appointments_resource_start"`
+	DurationMinutes int        `json:"duration_minutes" gorm:"not null"`
+	EndsAt          time.Time  `json:"ends_at" gorm:"not null"`
+	Version         int        `json:"version" gorm:"not null;default:1"`
+}
+
+// TableName sets the table name for Appointment
+func (Appointment) TableName() string {
+	return "wisdom.appointments"
+}
+
+// TimeSlot is a free interval returned by availability queries.
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions an audit entry records.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one change to one record. Changes maps each field that
+// changed to an AuditChange. Actor is the subject of the caller who made
+// the change, empty when there was none.
+type AuditEntry struct {
+	AuditID    uuid.UUID       `json:"audit_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OccurredAt time.Time       `json:"occurred_at" gorm:"not null"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action" gorm:"not null"`
+	Entity     string          `json:"entity" gorm:"not null"`
+	EntityID   string          `json:"entity_id" gorm:"not null"`
+	Changes    json.RawMessage `json:"changes" gorm:"type:jsonb;not null" swaggertype:"object"`
+}
+
+// TableName sets the table name for AuditEntry
+func (AuditEntry) TableName() string {
+	return "wisdom.audit_log"
+}
+
+// AuditChange is a field's value before and after a change. Old is null for
+// records being created and New for records being removed for good.
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "encoding/json"
+
+// Batch modes. An atomic batch is all-or-nothing; a partial batch keeps the
+// writes that succeed and reports the ones that fail.
+const (
+	BatchAtomic  = "atomic"
+	BatchPartial = "partial"
+)
+
+// Batch operations, as reported in BatchResult.Operation.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// Batch is a set of writes applied together in one transaction. Mode
+// defaults to atomic. Updates and deletes only apply to records still at
+// the version they carry, unless it is zero.
+type Batch[T any] struct {
+	Mode   string          `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	Create []T             `json:"create"`
+	Update []T             `json:"update"`
+	Delete []BatchDeletion `json:"delete"`
+}
+
+// BatchDeletion names a record to delete in a batch and the version it was
+// last read at. It may also be given as just the ID, which deletes whatever
+// version is current.
+type BatchDeletion struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+// UnmarshalJSON reads a BatchDeletion from an object or a bare ID.
+func (d *BatchDeletion) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*d = BatchDeletion{ID: id}
+		return nil
+	}
+
+	type batchDeletion BatchDeletion
+	return json.Unmarshal(data, (*batchDeletion)(d))
+}
+
+// BatchResult is the outcome of one write in a batch. Index is the write's
+// position within its operation's list, or its line in the file for
+// imports. Item is the stored record for creates and updates, ID the
+// deleted record's ID for deletes.
+type BatchResult[T any] struct {
+	Operation string
+	Index     int
+	ID        string
+	Item      *T
+	Err       error
+}
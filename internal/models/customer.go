@@ -2,15 +2,18 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Customer struct {
-	CustomerID uuid.UUID `json:"customer_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	FirstName  string    `json:"first_name"`
-	LastName   string    `json:"last_name"`
-	Email      string    `json:"email"`
-	Phone      string    `json:"phone"`
-	Address    string    `json:"address"`
+	CustomerID uuid.UUID      `json:"customer_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	FirstName  string         `json:"first_name" validate:"required"`
+	LastName   string         `json:"last_name" validate:"required"`
+	Email      string         `json:"email" validate:"required,email"`
+	Phone      string         `json:"phone" validate:"omitempty,phone"`
+	Address    string         `json:"address"`
+	Version    int            `json:"version" gorm:"not null;default:1"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // TableName sets the table name for Customer
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrPublisherUnavailable marks publish failures every event would meet,
+// such as a broker that is down or too slow to answer, so relaying stops
+// until later instead of trying the rest of the outbox.
+var ErrPublisherUnavailable = errors.New("publisher unavailable")
+
+// Event is a domain event, such as ProductPriceChanged, raised by a change
+// to an aggregate and kept in the outbox until it is published. Sequence
+// orders events as they were raised; events of one aggregate are published
+// in that order. Payload holds the fields that changed and the aggregate as
+// it is after the change.
+type Event struct {
+	EventID       uuid.UUID       `json:"event_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Sequence      int64           `json:"sequence" gorm:"->"`
+	Type          string          `json:"type" gorm:"not null"`
+	AggregateType string          `json:"aggregate_type" gorm:"not null"`
+	AggregateID   string          `json:"aggregate_id" gorm:"not null"`
+	Actor         string          `json:"actor"`
+	OccurredAt    time.Time       `json:"occurred_at" gorm:"not null"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+
+	PublishedAt *time.Time `json:"-"`
+	Attempts    int        `json:"-" gorm:"not null;default:0"`
+	LastError   *string    `json:"-"`
+}
+
+// TableName sets the table name for Event
+func (Event) TableName() string {
+	return "wisdom.outbox"
+}
+
+// EventPayload is the payload of an event. Changes is empty for events that
+// create or remove an aggregate.
+type EventPayload struct {
+	Changes map[string]AuditChange `json:"changes,omitempty"`
+	Data    interface{}            `json:"data"`
+}
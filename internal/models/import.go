@@ -0,0 +1,26 @@
+package models
+
+// MaxImportRows caps the number of rows in one import, which is applied in a
+// single transaction.
+const MaxImportRows = 10000
+
+// ImportOptions controls how an import is applied. Mode works as for
+// batches; a dry run applies every row and then rolls back, so the report
+// shows what the import would do without changing anything.
+type ImportOptions struct {
+	Mode   string `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// ImportRow is one record read from an import file. Line locates it in the
+// file for the error report. Columns are the columns the row gave, which are
+// the only ones a row updating a record writes. References holds related
+// records named by something other than their ID, such as a product's vendor
+// by name. Err is set when the row could not be read.
+type ImportRow[T any] struct {
+	Line       int
+	Item       T
+	Columns    []string
+	References map[string]string
+	Err        error
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Order struct {
+	OrderID    uuid.UUID   `json:"order_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID uuid.UUID   `json:"customer_id" gorm:"type:uuid;not null;index"`
+	Customer   *Customer   `json:"-" gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:RESTRICT"`
+	OrderedAt  time.Time   `json:"ordered_at" gorm:"not null"`
+	Total      float64     `json:"total" gorm:"type:numeric(12,2)"`
+	Version    int         `json:"version" gorm:"not null;default:1"`
+	Lines      []OrderLine `json:"lines" gorm:"foreignKey:OrderID;references:OrderID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName sets the table name for Order
+func (Order) TableName() string {
+	return "wisdom.orders"
+}
+
+// OrderLine points at either a product or a service. UnitPrice is copied
+// from the catalogue when the order is placed so later price changes do
+// not rewrite history.
+type OrderLine struct {
+	OrderLineID uuid.UUID  `json:"order_line_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OrderID     uuid.UUID  `json:"order_id" gorm:"type:uuid;not null;index"`
+	ProductID   *uuid.UUID `json:"product_id,omitempty" gorm:"type:uuid"`
+	Product     *Product   `json:"-" gorm:"foreignKey:ProductID;references:ProductID;constraint:OnDelete:RESTRICT"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty" gorm:"type:uuid"`
+	Service     *Service   `json:"-" gorm:"foreignKey:ServiceID;references:ServiceID;constraint:OnDelete:RESTRICT"`
+	Quantity    int        `json:"quantity" gorm:"not null"`
+	UnitPrice   float64    `json:"unit_price" gorm:"type:numeric(12,2)"`
+}
+
+// TableName sets the table name for OrderLine
+func (OrderLine) TableName() string {
+	return "wisdom.order_lines"
+}
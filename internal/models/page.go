@@ -0,0 +1,34 @@
+package models
+
+// PageRequest selects one page of a listing. A Cursor from a previous page
+// takes precedence over PageIndex and continues from that page's edge.
+//
+// Filters narrow the listing with expressions such as "price>=10", and Sort
+// orders it by a comma separated field list such as "-price,name", where a
+// leading minus sorts that field in descending order.
+type PageRequest struct {
+	PageIndex    string
+	PageSize     string
+	Cursor       string
+	IncludeTotal bool
+	Filters      []string
+	Sort         string
+}
+
+// Page is one page of a listing. NextCursor and PrevCursor are opaque; pass
+// one back as PageRequest.Cursor to move through the listing. Total is only
+// set when it was asked for.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// SearchHit is one ranked search result. Highlight is the matched text,
+// escaped as HTML, with the matching words wrapped in <mark> tags.
+type SearchHit[T any] struct {
+	Item      T       `json:"item"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Pet struct {
+	PetID           uuid.UUID  `json:"pet_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID      uuid.UUID  `json:"customer_id" gorm:"type:uuid;not null;index"`
+	Customer        *Customer  `json:"-" gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE"`
+	Name            string     `json:"name" gorm:"not null" validate:"required"`
+	Species         string     `json:"species" gorm:"not null" validate:"required"`
+	Breed           string     `json:"breed"`
+	BirthDate       *time.Time `json:"birth_date,omitempty" gorm:"type:date"`
+	MicrochipNumber *string    `json:"microchip_number,omitempty" gorm:"uniqueIndex"`
+	Version         int        `json:"version" gorm:"not null;default:1"`
+}
+
+// TableName sets the table name for Pet
+func (Pet) TableName() string {
+	return "wisdom.pets"
+}
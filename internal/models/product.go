@@ -2,16 +2,37 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Product struct {
 	ProductID uuid.UUID `json:"product_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name      string    `json:"name" gorm:"unique;not null"`
-	Price     float64   `json:"price" gorm:"type:numeric(12,2)"`
-	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null,foreignKey:VendorID"`
+	Name      string    `json:"name" gorm:"unique;not null" validate:"required"`
+	Price     float64   `json:"price" gorm:"type:numeric(12,2)" validate:"gte=0"`
+	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index" validate:"required"`
+	Vendor    *Vendor   `json:"-" gorm:"foreignKey:VendorID;references:VendorID;constraint:OnDelete:RESTRICT"`
+
+	// Stock is only changed through the stock movement ledger
+	QuantityOnHand   int `json:"quantity_on_hand" gorm:"not null;default:0"`
+	ReorderThreshold int `json:"reorder_threshold" gorm:"not null;default:0" validate:"gte=0"`
+
+	// Version counts writes to the product and backs its ETag
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // TableName sets the table name for Product
 func (Product) TableName() string {
 	return "wisdom.products"
 }
+
+// StockLevel returns the product's current stock position
+func (p Product) StockLevel() *StockLevel {
+	return &StockLevel{
+		ProductID:        p.ProductID,
+		QuantityOnHand:   p.QuantityOnHand,
+		ReorderThreshold: p.ReorderThreshold,
+		LowStock:         p.QuantityOnHand <= p.ReorderThreshold,
+		Version:          p.Version,
+	}
+}
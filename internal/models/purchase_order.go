@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purchase order statuses.
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// purchaseOrderTransitions lists the statuses each status may move to.
+var purchaseOrderTransitions = map[string][]string{
+	PurchaseOrderDraft:             {PurchaseOrderSent, PurchaseOrderCancelled},
+	PurchaseOrderSent:              {PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled},
+	PurchaseOrderPartiallyReceived: {PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled},
+	PurchaseOrderReceived:          {},
+	PurchaseOrderCancelled:         {},
+}
+
+type PurchaseOrder struct {
+	PurchaseOrderID uuid.UUID           `json:"purchase_order_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VendorID        uuid.UUID           `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Vendor          *Vendor             `json:"-" gorm:"foreignKey:VendorID;references:VendorID;constraint:OnDelete:RESTRICT"`
+	Status          string              `json:"status" gorm:"not null"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	Version         int                 `json:"version" gorm:"not null;default:1"`
+	Lines           []PurchaseOrderLine `json:"lines" gorm:"foreignKey:PurchaseOrderID;references:PurchaseOrderID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName sets the table name for PurchaseOrder
+func (PurchaseOrder) TableName() string {
+	return "wisdom.purchase_orders"
+}
+
+// CanTransition reports whether the purchase order may move to status.
+func (p PurchaseOrder) CanTransition(status string) bool {
+	for _, next := range purchaseOrderTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+type PurchaseOrderLine struct {
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	PurchaseOrderID     uuid.UUID `json:"purchase_order_id" gorm:"type:uuid;not null;index"`
+	ProductID           uuid.UUID `json:"product_id" gorm:"type:uuid;not null"`
+	Product             *Product  `json:"-" gorm:"foreignKey:ProductID;references:ProductID;constraint:OnDelete:RESTRICT"`
+	QuantityOrdered     int       `json:"quantity_ordered" gorm:"not null"`
+	QuantityReceived    int       `json:"quantity_received" gorm:"not null;default:0"`
+	UnitCost            float64   `json:"unit_cost" gorm:"type:numeric(12,2)"`
+}
+
+// TableName sets the table name for PurchaseOrderLine
+func (PurchaseOrderLine) TableName() string {
+	return "wisdom.purchase_order_lines"
+}
+
+// PurchaseOrderReceipt is the quantity of a product delivered against a purchase order.
+type PurchaseOrderReceipt struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
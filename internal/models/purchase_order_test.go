@@ -0,0 +1,42 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+var purchaseOrderStatuses = []string{
+	PurchaseOrderDraft,
+	PurchaseOrderSent,
+	PurchaseOrderPartiallyReceived,
+	PurchaseOrderReceived,
+	PurchaseOrderCancelled,
+}
+
+// A purchase order moves forward from draft to received, can be cancelled
+// until everything has arrived, and never leaves received or cancelled.
+func TestPurchaseOrderCanTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		allowed []string
+	}{
+		{PurchaseOrderDraft, []string{PurchaseOrderSent, PurchaseOrderCancelled}},
+		{PurchaseOrderSent, []string{PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled}},
+		{PurchaseOrderPartiallyReceived, []string{PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled}},
+		{PurchaseOrderReceived, nil},
+		{PurchaseOrderCancelled, nil},
+		{"archived", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.from, func(t *testing.T) {
+			order := PurchaseOrder{Status: test.from}
+			for _, to := range append(purchaseOrderStatuses, "archived", "") {
+				want := slices.Contains(test.allowed, to)
+				if got := order.CanTransition(to); got != want {
+					t.Errorf("CanTransition(%q) = %t, want %t", to, got, want)
+				}
+			}
+		})
+	}
+}
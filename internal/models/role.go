@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Role grants a set of permissions, each a resource and an action such as
+// products:write. Either half may be * to match any.
+type Role struct {
+	RoleID      uuid.UUID      `json:"role_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string         `json:"name" gorm:"unique;not null" validate:"required"`
+	Description string         `json:"description"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[];not null" validate:"dive,permission" swaggertype:"array,string"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+}
+
+// TableName sets the table name for Role
+func (Role) TableName() string {
+	return "wisdom.roles"
+}
+
+// RoleAssignment gives the caller whose token has Subject as its sub claim
+// the permissions of a role.
+type RoleAssignment struct {
+	Subject   string    `json:"subject" gorm:"primaryKey" validate:"required"`
+	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;primaryKey"`
+	Role      *Role     `json:"-" gorm:"foreignKey:RoleID;references:RoleID;constraint:OnDelete:RESTRICT"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName sets the table name for RoleAssignment
+func (RoleAssignment) TableName() string {
+	return "wisdom.role_assignments"
+}
@@ -2,12 +2,15 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Service struct {
-	ServiceID uuid.UUID `json:"service_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name      string    `json:"name" gorm:"unique;not null"`
-	Price     float64   `json:"price" gorm:"type:numeric(12,2)"`
+	ServiceID uuid.UUID      `json:"service_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name      string         `json:"name" gorm:"unique;not null" validate:"required"`
+	Price     float64        `json:"price" gorm:"type:numeric(12,2)" validate:"gte=0"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // TableName sets the table name for Service
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of stock movement recorded in the ledger.
+const (
+	StockReceipt    = "receipt"
+	StockSale       = "sale"
+	StockAdjustment = "adjustment"
+)
+
+// StockMovement is an append-only ledger entry. Quantity is the signed
+// change applied to the product's on-hand quantity.
+type StockMovement struct {
+	StockMovementID uuid.UUID `json:"stock_movement_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ProductID       uuid.UUID `json:"product_id" gorm:"type:uuid;not null;index"`
+	Product         *Product  `json:"-" gorm:"foreignKey:ProductID;references:ProductID;constraint:OnDelete:CASCADE"`
+	Kind            string    `json:"kind" gorm:"not null"`
+	Quantity        int       `json:"quantity" gorm:"not null"`
+	BalanceAfter    int       `json:"balance_after" gorm:"not null"`
+	Reference       string    `json:"reference"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null"`
+}
+
+// TableName sets the table name for StockMovement
+func (StockMovement) TableName() string {
+	return "wisdom.stock_movements"
+}
+
+// StockLevel is the current stock position of a product.
+type StockLevel struct {
+	ProductID        uuid.UUID `json:"product_id"`
+	QuantityOnHand   int       `json:"quantity_on_hand"`
+	ReorderThreshold int       `json:"reorder_threshold"`
+	LowStock         bool      `json:"low_stock"`
+	Version          int       `json:"version"`
+}
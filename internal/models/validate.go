@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+)
+
+// phoneNumber accepts an optional leading + followed by digits that may be
+// grouped with spaces, dots, dashes or parentheses, e.g. (991) 321-6632.
+var phoneNumber = regexp.MustCompile(`^\+?[0-9 ().-]{7,20}$`)
+
+// permission is a resource and an action, either of which may be *, e.g.
+// products:write or customers:*. purge removes records for good, where
+// delete only soft deletes them.
+var permission = regexp.MustCompile(`^([a-z-]+|\*):(read|write|delete|purge|\*)$`)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name, which is what clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return phoneNumber.MatchString(fl.Field().String())
+	})
+
+	v.RegisterValidation("permission", func(fl validator.FieldLevel) bool {
+		return permission.MatchString(fl.Field().String())
+	})
+
+	return v
+}
+
+// Validate checks v against the validate tags on its fields. When any rule
+// fails it returns dberrors.ValidationErrors with one entry per field. Values
+// that are not structs carry no rules and always pass.
+func Validate(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := validate.Struct(value.Interface())
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	errs := make(dberrors.ValidationErrors, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		errs = append(errs, dberrors.ValidationError{Field: fieldError.Field(), Message: message(fieldError)})
+	}
+	return errs
+}
+
+// message describes a failed rule in the same words the rest of the API uses.
+func message(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a valid phone number"
+	case "permission":
+		return "must be a resource and an action such as products:write"
+	case "gte":
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "min":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(fieldError.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldError.Tag())
+	}
+}
@@ -2,15 +2,19 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Vendor struct {
-	VendorID uuid.UUID `json:"vendor_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name     string    `json:"name" gorm:"not null"`
-	Contact  string    `json:"contact"`
-	Phone    string    `json:"phone"`
-	Email    string    `json:"email"`
-	Address  string    `json:"address"`
+	VendorID  uuid.UUID      `json:"vendor_id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name      string         `json:"name" gorm:"not null" validate:"required"`
+	Contact   string         `json:"contact"`
+	Phone     string         `json:"phone" validate:"omitempty,phone"`
+	Email     string         `json:"email" validate:"omitempty,email"`
+	Address   string         `json:"address"`
+	Products  []Product      `json:"-" gorm:"foreignKey:VendorID;references:VendorID"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // TableName sets the table name for Vendor
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/johnifegwu/go-microservices/internal/auth"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllAPIKeys godoc
+// @Summary Get all API keys
+// @Description Retrieve issued API keys, revoked and expired ones included, newest first. The keys themselves are never returned
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as name~sync or expires_at<2027-01-01; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -last_used_at"
+// @Success 200 {object} models.Page[models.APIKey]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys [get]
+func (s *EchoServer) GetAllAPIKeys(ctx echo.Context) error {
+	keys, err := s.DB.GetAllAPIKeys(ctx.Request().Context(), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, keys)
+}
+
+// GetAPIKeyById godoc
+// @Summary Get API key by ID
+// @Description Retrieve an API key's name, permissions, expiry and last use by its ID
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys/{id} [get]
+func (s *EchoServer) GetAPIKeyById(ctx echo.Context) error {
+	key, err := s.DB.GetAPIKeyById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, key)
+}
+
+// AddAPIKey godoc
+// @Summary Issue an API key
+// @Description Issue a key for a script or job that cannot log in interactively. Only name, permissions and expires_at are read from the body. The key is returned once and cannot be read again. Callers can only grant permissions they hold themselves
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param key body models.APIKey true "Name, permissions and optional expiry of the key"
+// @Success 201 {object} server.CreatedAPIKey
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission, or granting one the caller does not hold"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys [post]
+func (s *EchoServer) AddAPIKey(ctx echo.Context) error {
+	request := new(models.APIKey)
+
+	if err := ctx.Bind(request); err != nil {
+		return err
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return &dberrors.ValidationError{Field: "expires_at", Message: "must be in the future"}
+	}
+
+	key := &models.APIKey{
+		Name:        request.Name,
+		Permissions: request.Permissions,
+		ExpiresAt:   request.ExpiresAt,
+	}
+
+	// Without this a caller allowed to issue keys could give itself anything
+	if err := s.grantable(ctx.Request().Context(), key.Permissions); err != nil {
+		return err
+	}
+	if principal, ok := auth.PrincipalFrom(ctx.Request().Context()); ok {
+		key.CreatedBy = principal.Subject
+	}
+
+	secret, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return err
+	}
+	key.Prefix = prefix
+	key.Hash = auth.HashAPIKey(secret)
+
+	key, err = s.DB.AddAPIKey(ctx.Request().Context(), key)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, server.CreatedAPIKey{APIKey: *key, Key: secret})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Stop accepting an API key. The key stays listed with its revoked_at time; revoking it again changes nothing
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys/{id} [delete]
+func (s *EchoServer) RevokeAPIKey(ctx echo.Context) error {
+	key, err := s.DB.RevokeAPIKey(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, key)
+}
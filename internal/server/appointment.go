@@ -0,0 +1,173 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllAppointments godoc
+// @Summary Get all appointments
+// @Description Get appointments with optional filtering by day, resource and customer, and pagination
+// @Tags appointments
+// @Accept  json
+// @Produce  json
+// @Param date query string false "Day to list (YYYY-MM-DD)"
+// @Param resource query string false "Resource (room, vet) for filtering"
+// @Param customer_id query string false "Customer ID for filtering"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.Appointment]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /appointments [get]
+func (s *EchoServer) GetAllAppointments(ctx echo.Context) error {
+	day := ctx.QueryParam("date")
+	resource := ctx.QueryParam("resource")
+	customerid := ctx.QueryParam("customer_id")
+
+	appointments, err := s.DB.GetAllAppointments(ctx.Request().Context(), day, resource, customerid, pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, appointments)
+}
+
+// GetAvailability godoc
+// @Summary Get free time slots
+// @Description Get the free intervals of a resource within opening hours on a given day
+// @Tags appointments
+// @Accept  json
+// @Produce  json
+// @Param date query string true "Day to check (YYYY-MM-DD)"
+// @Param resource query string false "Resource (room, vet) to check"
+// @Param duration query string false "Minimum slot length in minutes"
+// @Success 200 {array} models.TimeSlot
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /appointments/availability [get]
+func (s *EchoServer) GetAvailability(ctx echo.Context) error {
+	day := ctx.QueryParam("date")
+	resource := ctx.QueryParam("resource")
+	duration := ctx.QueryParam("duration")
+
+	slots, err := s.DB.GetAvailability(ctx.Request().Context(), resource, day, duration)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, slots)
+}
+
+// GetAppointmentById godoc
+// @Summary Get appointment by ID
+// @Description Get a single appointment by its ID
+// @Tags appointments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Appointment ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.Appointment
+// @Header 200 {string} ETag "Version of the appointment"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /appointments/{id} [get]
+func (s *EchoServer) GetAppointmentById(ctx echo.Context) error {
+	id := ctx.Param("id")
+	appointment, err := s.DB.GetAppointmentById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, appointment.Version, appointment)
+}
+
+// AddAppointment godoc
+// @Summary Book an appointment
+// @Description Book a service for a customer (and optionally their pet) at a start time with a duration
+// @Tags appointments
+// @Accept  json
+// @Produce  json
+// @Param appointment body models.Appointment true "Appointment to book"
+// @Success 201 {object} models.Appointment
+// @Header 201 {string} ETag "Version of the appointment"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /appointments [post]
+func (s *EchoServer) AddAppointment(ctx echo.Context) error {
+	appointment := new(models.Appointment)
+
+	if err := ctx.Bind(appointment); err != nil {
+		return err
+	}
+
+	appointment, err := s.DB.AddAppointment(ctx.Request().Context(), appointment)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, appointment.Version, appointment)
+}
+
+// DeleteAppointment godoc
+// @Summary Cancel an appointment
+// @Description Delete an appointment by its ID, freeing its time slot. The former DELETE /appointments?id= still works but is deprecated
+// @Tags appointments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string true "ETag of the appointment as last read, or * for any version"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The appointment has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /appointments/{id} [delete]
+func (s *EchoServer) DeleteAppointment(ctx echo.Context) error {
+	var appointmentId = resourceID(ctx)
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteAppointment(ctx.Request().Context(), appointmentId, version)
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record deleted successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
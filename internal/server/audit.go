@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// GetAuditEntries godoc
+// @Summary Get the audit trail
+// @Description List recorded creates, updates and deletes, most recent first. Each entry names who made the change and holds the old and new value of every field it changed
+// @Tags audit
+// @Accept  json
+// @Produce  json
+// @Param entity query string false "Entity for filtering, such as product, customer or purchase_order"
+// @Param id query string false "ID of the record for filtering; records with several keys join them with /"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as actor=alice or occurred_at>=2026-01-01; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -occurred_at"
+// @Success 200 {object} models.Page[models.AuditEntry]
+// @Failure 400 {object} server.ErrorResponse "Unknown entity, or invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /audit [get]
+func (s *EchoServer) GetAuditEntries(ctx echo.Context) error {
+	entries, err := s.DB.GetAuditEntries(ctx.Request().Context(), ctx.QueryParam("entity"), ctx.QueryParam("id"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, entries)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/johnifegwu/go-microservices/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// authenticate requires a valid bearer token or API key on every route but
+// the public ones and puts the principal it identifies into the request
+// context, where handlers and the database client can find it with
+// auth.PrincipalFrom.
+func (s *EchoServer) authenticate(verifier *auth.Verifier) echo.MiddlewareFunc {
+	public := map[string]bool{}
+	for _, route := range s.config.Auth.PublicRoutes {
+		public[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			// Path is the route pattern the request matched, e.g. /swagger/*.
+			// Requests matching no route are left to answer 404 or 405
+			if public[ctx.Path()] || unrouted(ctx) {
+				return next(ctx)
+			}
+
+			request := ctx.Request()
+			var principal *auth.Principal
+			if key := request.Header.Get(auth.APIKeyHeader); key != "" {
+				var err error
+				principal, err = s.apiKeyPrincipal(request.Context(), key)
+				if err != nil {
+					return err
+				}
+			} else {
+				token, ok := bearerToken(request.Header.Get(echo.HeaderAuthorization))
+				if !ok {
+					ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer`)
+					return echo.NewHTTPError(http.StatusUnauthorized, "a bearer token or API key is required")
+				}
+
+				var err error
+				principal, err = verifier.Verify(request.Context(), token)
+				if err != nil {
+					// Why a token was refused is for our logs, not for the caller
+					ctx.Logger().Warnf("rejected bearer token: %s", err)
+					ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+				}
+			}
+
+			ctx.SetRequest(request.WithContext(auth.WithPrincipal(request.Context(), principal)))
+			return next(ctx)
+		}
+	}
+}
+
+// unrouted reports whether the request matched no route, in which case
+// Echo has picked its 404 or 405 handler.
+func unrouted(ctx echo.Context) bool {
+	handler := reflect.ValueOf(ctx.Handler()).Pointer()
+	return handler == reflect.ValueOf(echo.NotFoundHandler).Pointer() ||
+		handler == reflect.ValueOf(echo.MethodNotAllowedHandler).Pointer()
+}
+
+// apiKeyPrincipal identifies the caller presenting key. Unknown, revoked and
+// expired keys all get the same answer so a caller cannot tell them apart.
+func (s *EchoServer) apiKeyPrincipal(ctx context.Context, key string) (*auth.Principal, error) {
+	invalid := echo.NewHTTPError(http.StatusUnauthorized, "invalid API key")
+
+	prefix, ok := auth.APIKeyPrefix(key)
+	if !ok {
+		return nil, invalid
+	}
+	apiKey, err := s.DB.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || !auth.MatchAPIKey(key, apiKey.Hash) || !apiKey.Active(time.Now()) {
+		return nil, invalid
+	}
+
+	if err := s.DB.TouchAPIKey(ctx, apiKey.APIKeyID); err != nil {
+		return nil, err
+	}
+
+	return &auth.Principal{
+		Subject:     "api-key:" + apiKey.APIKeyID.String(),
+		Permissions: apiKey.Permissions,
+	}, nil
+}
+
+// bearerToken takes the token out of an Authorization header using the
+// Bearer scheme, which RFC 9110 makes case-insensitive.
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
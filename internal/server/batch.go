@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// writeBatch responds with the outcome of each write in a batch. The
+// response is 200 when every write succeeded and 207 Multi-Status otherwise.
+func writeBatch[T any](ctx echo.Context, mode string, results []models.BatchResult[T]) error {
+	response := server.BatchResponse{Mode: mode}
+	response.Results, response.Succeeded, response.Failed = batchItems(ctx, results)
+	return ctx.JSON(batchStatus(response.Failed), response)
+}
+
+// batchItems reports each write of results with the status it would have had
+// as a request of its own, counting the writes that succeeded and failed.
+func batchItems[T any](ctx echo.Context, results []models.BatchResult[T]) ([]server.BatchItemResult, int, int) {
+	items := make([]server.BatchItemResult, len(results))
+	succeeded, failed := 0, 0
+
+	for i, result := range results {
+		item := server.BatchItemResult{Operation: result.Operation, Index: result.Index, ID: result.ID}
+
+		if result.Err != nil {
+			status, body := mapError(result.Err)
+			if status == http.StatusInternalServerError {
+				ctx.Logger().Error(result.Err)
+			}
+			item.Status = status
+			item.Error = &body
+			failed++
+		} else {
+			item.Status = http.StatusOK
+			if result.Operation == models.BatchCreate {
+				item.Status = http.StatusCreated
+			}
+			if result.Item != nil {
+				item.Item = result.Item
+			}
+			succeeded++
+		}
+
+		items[i] = item
+	}
+
+	return items, succeeded, failed
+}
+
+func batchStatus(failed int) int {
+	if failed > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// binder binds requests like echo.DefaultBinder and then validates the bound
+// value, so every handler that binds a payload applies the model's rules.
+type binder struct {
+	echo.DefaultBinder
+}
+
+func (b *binder) Bind(i interface{}, ctx echo.Context) error {
+	if err := b.DefaultBinder.Bind(i, ctx); err != nil {
+		return err
+	}
+	return models.Validate(i)
+}
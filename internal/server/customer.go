@@ -2,11 +2,13 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/johnifegwu/go-microservices/internal/transfer"
 	"github.com/labstack/echo/v4"
 )
 
@@ -19,18 +21,28 @@ import (
 // @Param email query string false "Email address for filtering"
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Customer
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Param include_deleted query bool false "Include soft-deleted records"
+// @Success 200 {object} models.Page[models.Customer]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /customers [get]
 func (s *EchoServer) GetAllCustomers(ctx echo.Context) error {
 	email := ctx.QueryParam("email")
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
 
-	customers, err := s.DB.GetAllCustomers(ctx.Request().Context(), email, pageindex, pagesize)
+	includeDeleted, _ := strconv.ParseBool(ctx.QueryParam("include_deleted"))
+
+	customers, err := s.DB.GetAllCustomers(ctx.Request().Context(), email, pageRequest(ctx), includeDeleted)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, customers)
+	return writePage(ctx, customers)
 }
 
 // GetCustomerById godoc
@@ -40,15 +52,22 @@ func (s *EchoServer) GetAllCustomers(ctx echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Customer ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
 // @Success 200 {object} models.Customer
+// @Header 200 {string} ETag "Version of the customer"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /customers/{id} [get]
 func (s *EchoServer) GetCustomerById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	products, err := s.DB.GetCustomerById(ctx.Request().Context(), id)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, products)
+	return writeVersioned(ctx, http.StatusOK, products.Version, products)
 }
 
 // AddCustomer godoc
@@ -59,85 +78,160 @@ func (s *EchoServer) GetCustomerById(ctx echo.Context) error {
 // @Produce  json
 // @Param customer body models.Customer true "Customer to add"
 // @Success 201 {object} models.Customer
-// @Failure 409 {object} dberrors.ConflictError
-// @Failure 415 {object} string "Unsupported Media Type"
+// @Header 201 {string} ETag "Version of the customer"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /customers [post]
 func (s *EchoServer) AddCustomer(ctx echo.Context) error {
 	customer := new(models.Customer)
 
 	if err := ctx.Bind(customer); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
 	customer, err := s.DB.AddCustomer(ctx.Request().Context(), customer)
 
 	if err != nil {
-		switch err.(type) {
-		case *dberrors.ConflictError:
-			return ctx.JSON(http.StatusConflict, err)
-		default:
-			return ctx.JSON(http.StatusInternalServerError, err)
-		}
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, customer)
+	return writeVersioned(ctx, http.StatusCreated, customer.Version, customer)
 }
 
 // UpdateCustomer godoc
 // @Summary Update an existing customer
-// @Description Update a customer's details by providing its ID
+// @Description Replace a customer's details. The ID on the path wins; the body may repeat it or leave it out. The former PUT /customers that took the ID from the body still works but is deprecated
 // @Tags customers
 // @Accept  json
 // @Produce  json
-// @Param customer_id path string true "Customer ID"
+// @Param id path string true "Customer ID"
 // @Param customer body models.Customer true "Updated customer data"
+// @Param If-Match header string true "ETag of the customer as last read, or * for any version"
 // @Success 201 {object} models.Customer
-// @Failure 400 {object} string "Bad Request"
-// @Failure 415 {object} string "Unsupported Media Type"
-// @Router /customers/{customer_id} [put]
+// @Header 201 {string} ETag "Version of the customer"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The customer has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id} [put]
 func (s *EchoServer) UpdateCustomer(ctx echo.Context) error {
 	customer := new(models.Customer)
 
 	if err := ctx.Bind(customer); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
-	ID, errUUID := uuid.Parse(ctx.Param("customer_id"))
-	if errUUID == nil {
-		if ID != customer.CustomerID {
-			return ctx.JSON(http.StatusBadRequest, "id on path doesn't match id on body")
+	// The legacy route has no ID on the path and takes the one in the body
+	if id := ctx.Param("id"); id != "" {
+		ID, err := dberrors.ParseID(id)
+		if err != nil {
+			return err
 		}
+		if customer.CustomerID != uuid.Nil && customer.CustomerID != ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
+		}
+		customer.CustomerID = ID
 	}
 
-	customer, err := s.DB.UpdateCustomer(ctx.Request().Context(), customer)
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+	customer.Version = version
+
+	customer, err = s.DB.UpdateCustomer(ctx.Request().Context(), customer)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, customer)
+	return writeVersioned(ctx, http.StatusCreated, customer.Version, customer)
+}
+
+// PatchCustomer godoc
+// @Summary Patch a customer
+// @Description Change only some fields of a customer with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Fields the patch leaves alone keep their stored values
+// @Tags customers
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string true "ETag of the customer as last read, or * for any version"
+// @Success 200 {object} models.Customer
+// @Header 200 {string} ETag "Version of the customer"
+// @Failure 400 {object} server.ErrorResponse "Malformed patch"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The customer has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "The patch cannot be applied or the result is invalid"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id} [patch]
+func (s *EchoServer) PatchCustomer(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	apply, err := readPatch[models.Customer](ctx)
+	if err != nil {
+		return err
+	}
+
+	customer, err := s.DB.PatchCustomer(ctx.Request().Context(), ctx.Param("id"), version, apply)
+	if err != nil {
+		return err
+	}
+
+	return writeVersioned(ctx, http.StatusOK, customer.Version, customer)
 }
 
 // DeleteCustomer godoc
 // @Summary Delete a customer
-// @Description Delete a customer from the database by its ID
+// @Description Soft-delete a customer by its ID. The record is hidden but can be restored. The former DELETE /customers?id= still works but is deprecated
 // @Tags customers
 // @Accept  json
 // @Produce  json
-// @Param id query string true "Customer ID"
+// @Param id path string true "Customer ID"
+// @Param If-Match header string true "ETag of the customer as last read, or * for any version"
 // @Success 200 {object} server.Response
-// @Failure 500 {object} dberrors.ZeroRowsAffectedError
-// @Router /customers [delete]
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The customer has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id} [delete]
 func (s *EchoServer) DeleteCustomer(ctx echo.Context) error {
-	var customerId = ctx.QueryParam("id")
+	var customerId = resourceID(ctx)
 
-	rowsaffected, err := s.DB.DeleteCustomer(ctx.Request().Context(), customerId)
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteCustomer(ctx.Request().Context(), customerId, version)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
 	if rowsaffected < 1 {
-		dberr := new(dberrors.ZeroRowsAffectedError)
-		return ctx.JSON(http.StatusInternalServerError, dberr.Error())
+		return &dberrors.ZeroRowsAffectedError{}
 	}
 
 	response := server.Response{
@@ -146,3 +240,135 @@ func (s *EchoServer) DeleteCustomer(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// RestoreCustomer godoc
+// @Summary Restore a deleted customer
+// @Description Bring back a soft-deleted customer
+// @Tags customers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} models.Customer
+// @Header 200 {string} ETag "Version of the customer"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/restore [post]
+func (s *EchoServer) RestoreCustomer(ctx echo.Context) error {
+	customer, err := s.DB.RestoreCustomer(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, customer.Version, customer)
+}
+
+// PurgeCustomer godoc
+// @Summary Permanently delete a customer
+// @Description Remove a soft-deleted customer for good. Refused while orders or appointments still reference it. Needs customers:purge, which only the admin role holds by default
+// @Tags customers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/purge [delete]
+func (s *EchoServer) PurgeCustomer(ctx echo.Context) error {
+	rowsaffected, err := s.DB.PurgeCustomer(ctx.Request().Context(), ctx.Param("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record purged successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// BatchCustomers godoc
+// @Summary Create, update and delete customers in one batch
+// @Description Apply many writes in a single transaction. In atomic mode (the default) any failure rolls back the whole batch; in partial mode successful writes are kept. Each write's outcome is reported with its own status
+// @Tags customers
+// @Accept  json
+// @Produce  json
+// @Param batch body models.Batch[models.Customer] true "Writes to apply"
+// @Success 200 {object} server.BatchResponse "Every write succeeded"
+// @Success 207 {object} server.BatchResponse "At least one write failed"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers:batch [post]
+func (s *EchoServer) BatchCustomers(ctx echo.Context) error {
+	batch := new(models.Batch[models.Customer])
+
+	if err := ctx.Bind(batch); err != nil {
+		return err
+	}
+
+	results, err := s.DB.BatchCustomers(ctx.Request().Context(), batch)
+	if err != nil {
+		return err
+	}
+	return writeBatch(ctx, batch.Mode, results)
+}
+
+// ExportCustomers godoc
+// @Summary Export customers
+// @Description Stream every live customer as a CSV or JSON lines download. CSV files start with a header row of column names, and cells a spreadsheet would run as a formula are prefixed with ' (importing the file removes it again); JSON lines hold one customer per line as the API returns it. Filters narrow the export as they do the listing
+// @Tags customers
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "File format" Enums(csv, jsonl) default(csv)
+// @Param filter query []string false "Filters of the form field<operator>value, e.g. name~acme" collectionFormat(multi)
+// @Success 200 {file} file
+// @Failure 400 {object} server.ErrorResponse "Invalid format or filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/export [get]
+func (s *EchoServer) ExportCustomers(ctx echo.Context) error {
+	return writeExport(ctx, "customers", transfer.Customers, s.DB.ExportCustomers)
+}
+
+// ImportCustomers godoc
+// @Summary Import customers
+// @Description Create and update customers from a CSV or JSON lines file, as exported. Rows without customer_id create a customer, rows with one update the columns they give, leaving the rest unchanged. Headers that differ from the column names can be mapped with map=header=column. In atomic mode (the default) any failing row rolls back the whole import; in partial mode the good rows are kept. A dry run applies every row and then rolls back. Each row is reported by its line in the file.
+// @Tags customers
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Produce  json
+// @Param file body string true "Rows to import"
+// @Param format query string false "File format, taken from the Content-Type when omitted" Enums(csv, jsonl)
+// @Param map query []string false "Header mappings of the form header=column" collectionFormat(multi)
+// @Param mode query string false "Import mode" Enums(atomic, partial) default(atomic)
+// @Param dry_run query bool false "Validate and report without keeping any change"
+// @Success 200 {object} server.ImportResponse "Every row succeeded"
+// @Success 207 {object} server.ImportResponse "At least one row failed"
+// @Failure 400 {object} server.ErrorResponse "Invalid format, map or dry_run"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unreadable header, empty file or too many rows"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/import [post]
+func (s *EchoServer) ImportCustomers(ctx echo.Context) error {
+	return readImport(ctx, transfer.Customers, s.DB.ImportCustomers)
+}
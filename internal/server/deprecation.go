@@ -0,0 +1,33 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// legacyRoutesDeprecated is when PUT and DELETE on a collection, with the ID
+// in the body or ?id=, gave way to the same methods on /<entity>/:id.
+var legacyRoutesDeprecated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// deprecated marks every response of a legacy route with the Deprecation
+// header of RFC 9745 and links to the API documentation, so clients can find
+// the route that replaces it. The route itself keeps working.
+func deprecated(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		header := ctx.Response().Header()
+		header.Set("Deprecation", "@"+strconv.FormatInt(legacyRoutesDeprecated.Unix(), 10))
+		header.Add("Link", `</swagger/index.html>; rel="deprecation"; type="text/html"`)
+		return next(ctx)
+	}
+}
+
+// resourceID is the ID a request addresses: the :id path parameter, or the
+// id query parameter on the legacy routes that have none.
+func resourceID(ctx echo.Context) string {
+	if id := ctx.Param("id"); id != "" {
+		return id
+	}
+	return ctx.QueryParam("id")
+}
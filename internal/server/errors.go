@@ -0,0 +1,135 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// errorHandler is the Echo HTTPErrorHandler. Handlers return errors and this
+// turns them into a status code and a server.ErrorResponse body.
+func (s *EchoServer) errorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	status, body := mapError(err)
+	body.RequestID = ctx.Response().Header().Get(echo.HeaderXRequestID)
+
+	if status == http.StatusInternalServerError {
+		ctx.Logger().Error(err)
+	}
+
+	var writeErr error
+	if ctx.Request().Method == http.MethodHead {
+		writeErr = ctx.NoContent(status)
+	} else {
+		writeErr = ctx.JSON(status, body)
+	}
+	if writeErr != nil {
+		ctx.Logger().Error(writeErr)
+	}
+}
+
+// mapError picks the status code and body for err.
+func mapError(err error) (int, server.ErrorResponse) {
+	var (
+		notFound   *dberrors.NotFoundError
+		zeroRows   *dberrors.ZeroRowsAffectedError
+		conflict   *dberrors.ConflictError
+		referenced *dberrors.ReferencedError
+		transition *dberrors.InvalidTransitionError
+		invalidID  *dberrors.InvalidIDError
+		invalidRef *dberrors.InvalidReferenceError
+		query      *dberrors.InvalidQueryError
+		validation *dberrors.ValidationError
+		fields     dberrors.ValidationErrors
+		rolledBack *dberrors.RolledBackError
+		stale      *dberrors.PreconditionFailedError
+		httpError  *echo.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, server.ErrorResponse{Code: "not_found", Message: notFound.Error()}
+	case errors.As(err, &zeroRows):
+		return http.StatusNotFound, server.ErrorResponse{Code: "not_found", Message: zeroRows.Error()}
+	case errors.As(err, &conflict):
+		return http.StatusConflict, server.ErrorResponse{Code: "conflict", Message: conflict.Error()}
+	case errors.As(err, &referenced):
+		return http.StatusConflict, server.ErrorResponse{Code: "referenced", Message: referenced.Error()}
+	case errors.As(err, &transition):
+		return http.StatusConflict, server.ErrorResponse{Code: "invalid_transition", Message: transition.Error()}
+	case errors.As(err, &invalidID):
+		return http.StatusBadRequest, server.ErrorResponse{Code: "invalid_id", Message: invalidID.Error()}
+	case errors.As(err, &query):
+		return http.StatusBadRequest, server.ErrorResponse{Code: "invalid_query", Message: query.Error()}
+	case errors.As(err, &invalidRef):
+		return http.StatusUnprocessableEntity, server.ErrorResponse{Code: "invalid_reference", Message: invalidRef.Error()}
+	case errors.As(err, &fields):
+		return http.StatusUnprocessableEntity, server.ErrorResponse{
+			Code:    "validation_failed",
+			Message: "request failed validation",
+			Details: fieldErrors(fields),
+		}
+	case errors.As(err, &validation):
+		return http.StatusUnprocessableEntity, server.ErrorResponse{
+			Code:    "validation_failed",
+			Message: validation.Error(),
+			Details: fieldErrors(dberrors.ValidationErrors{*validation}),
+		}
+	case errors.As(err, &stale):
+		return http.StatusPreconditionFailed, server.ErrorResponse{Code: "precondition_failed", Message: stale.Error()}
+	case errors.As(err, &rolledBack):
+		return http.StatusFailedDependency, server.ErrorResponse{Code: "rolled_back", Message: rolledBack.Error()}
+	case errors.As(err, &httpError):
+		message := http.StatusText(httpError.Code)
+		if text, ok := httpError.Message.(string); ok {
+			message = text
+		}
+		return httpError.Code, server.ErrorResponse{Code: codeFor(httpError.Code), Message: message}
+	default:
+		return http.StatusInternalServerError, server.ErrorResponse{Code: "internal_error", Message: "internal server error"}
+	}
+}
+
+// fieldErrors lists validation failures in the shape clients receive.
+func fieldErrors(errs dberrors.ValidationErrors) []server.FieldError {
+	details := make([]server.FieldError, len(errs))
+	for i, err := range errs {
+		details[i] = server.FieldError{Field: err.Field, Message: err.Message}
+	}
+	return details
+}
+
+// codeFor names the error code used for a plain HTTP status.
+func codeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusPreconditionFailed:
+		return "precondition_failed"
+	case http.StatusPreconditionRequired:
+		return "precondition_required"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	default:
+		if status >= http.StatusInternalServerError {
+			return "internal_error"
+		}
+		return "error"
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// etag is the entity tag of a record at version. Every write bumps the
+// version, so the tag changes whenever the record does.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeVersioned responds with item and its ETag. A GET or HEAD whose
+// If-None-Match already names that tag gets 304 Not Modified instead.
+func writeVersioned(ctx echo.Context, status int, version int, item interface{}) error {
+	tag := etag(version)
+	ctx.Response().Header().Set("ETag", tag)
+
+	method := ctx.Request().Method
+	if (method == http.MethodGet || method == http.MethodHead) && noneMatch(ctx.Request().Header.Get("If-None-Match"), tag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(status, item)
+}
+
+// noneMatch reports whether an If-None-Match header names tag, using the
+// weak comparison RFC 9110 asks for.
+func noneMatch(header string, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns the version a PUT or DELETE is conditional on. If-Match is
+// required so clients cannot overwrite changes they have not seen; * accepts
+// whatever version is current and gives zero.
+func ifMatch(ctx echo.Context) (int, error) {
+	header := strings.TrimSpace(ctx.Request().Header.Get("If-Match"))
+	if header == "" {
+		return 0, echo.NewHTTPError(http.StatusPreconditionRequired, "If-Match is required; send the ETag of the record as last read")
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`))
+	if err != nil || version < 1 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, echo.NewHTTPError(http.StatusPreconditionFailed, "If-Match must be * or a single ETag returned by this API")
+	}
+	return version, nil
+}
@@ -0,0 +1,10 @@
+package server
+
+import "github.com/johnifegwu/go-microservices/internal/models"
+
+// CreatedAPIKey is a newly issued API key. Key is what clients send in the
+// X-API-Key header; it is only ever shown in this response.
+type CreatedAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
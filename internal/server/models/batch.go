@@ -0,0 +1,30 @@
+package server
+
+// BatchResponse reports the outcome of every write in a batch.
+type BatchResponse struct {
+	Mode      string            `json:"mode"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult is the outcome of one write in a batch. Status is the
+// HTTP status the write would have had as a request of its own.
+type BatchItemResult struct {
+	Operation string         `json:"operation"`
+	Index     int            `json:"index"`
+	ID        string         `json:"id,omitempty"`
+	Status    int            `json:"status"`
+	Item      interface{}    `json:"item,omitempty"`
+	Error     *ErrorResponse `json:"error,omitempty"`
+}
+
+// ImportResponse reports the outcome of every row of an import. The index of
+// each result is the row's line in the file.
+type ImportResponse struct {
+	Mode      string            `json:"mode"`
+	DryRun    bool              `json:"dry_run"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
@@ -0,0 +1,5 @@
+package server
+
+type StatusRequest struct {
+	Status string `json:"status"`
+}
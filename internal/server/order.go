@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllOrders godoc
+// @Summary Get all orders
+// @Description Get all orders with optional filtering by customer, and pagination
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param customer_id query string false "Customer ID for filtering"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /orders [get]
+func (s *EchoServer) GetAllOrders(ctx echo.Context) error {
+	customerid := ctx.QueryParam("customer_id")
+
+	orders, err := s.DB.GetAllOrders(ctx.Request().Context(), customerid, pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, orders)
+}
+
+// GetOrderById godoc
+// @Summary Get order by ID
+// @Description Get a single order and its lines by its ID
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.Order
+// @Header 200 {string} ETag "Version of the order"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /orders/{id} [get]
+func (s *EchoServer) GetOrderById(ctx echo.Context) error {
+	id := ctx.Param("id")
+	order, err := s.DB.GetOrderById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, order.Version, order)
+}
+
+// AddOrder godoc
+// @Summary Place a new order
+// @Description Place an order for a customer. Each line references either a product or a service; unit prices are taken from the catalogue at purchase time
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param order body models.Order true "Order to place"
+// @Success 201 {object} models.Order
+// @Header 201 {string} ETag "Version of the order"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /orders [post]
+func (s *EchoServer) AddOrder(ctx echo.Context) error {
+	order := new(models.Order)
+
+	if err := ctx.Bind(order); err != nil {
+		return err
+	}
+
+	order, err := s.DB.AddOrder(ctx.Request().Context(), order)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, order.Version, order)
+}
+
+// DeleteOrder godoc
+// @Summary Delete an order
+// @Description Delete an order and its lines from the database by its ID. The former DELETE /orders?id= still works but is deprecated
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param If-Match header string true "ETag of the order as last read, or * for any version"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The order has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /orders/{id} [delete]
+func (s *EchoServer) DeleteOrder(ctx echo.Context) error {
+	var orderId = resourceID(ctx)
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteOrder(ctx.Request().Context(), orderId, version)
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record deleted successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
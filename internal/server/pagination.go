@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// pageRequest reads the pagination, filter and sort query parameters shared
+// by every listing.
+func pageRequest(ctx echo.Context) models.PageRequest {
+	includeTotal, _ := strconv.ParseBool(ctx.QueryParam("include_total"))
+
+	return models.PageRequest{
+		PageIndex:    ctx.QueryParam("pageindex"),
+		PageSize:     ctx.QueryParam("pagesize"),
+		Cursor:       ctx.QueryParam("cursor"),
+		IncludeTotal: includeTotal,
+		Filters:      ctx.QueryParams()["filter"],
+		Sort:         ctx.QueryParam("sort"),
+	}
+}
+
+// writePage responds with page and advertises the neighbouring pages in an
+// RFC 8288 Link header.
+func writePage[T any](ctx echo.Context, page *models.Page[T]) error {
+	var links []string
+	if page.NextCursor != "" {
+		links = append(links, pageLink(ctx, page.NextCursor, "next"))
+	}
+	if page.PrevCursor != "" {
+		links = append(links, pageLink(ctx, page.PrevCursor, "prev"))
+	}
+	if len(links) > 0 {
+		ctx.Response().Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	return ctx.JSON(http.StatusOK, page)
+}
+
+// pageLink is a link to the current listing moved to cursor, keeping every
+// other query parameter the client sent.
+func pageLink(ctx echo.Context, cursor string, rel string) string {
+	target := *ctx.Request().URL
+	target.Scheme = ctx.Scheme()
+	target.Host = ctx.Request().Host
+
+	query := target.Query()
+	query.Del("pageindex")
+	query.Set("cursor", cursor)
+	target.RawQuery = query.Encode()
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel)
+}
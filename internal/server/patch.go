@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/labstack/echo/v4"
+)
+
+// Media types a PATCH body may be sent as.
+const (
+	mergePatch = "application/merge-patch+json"
+	jsonPatch  = "application/json-patch+json"
+)
+
+// readPatch reads the body of a PATCH request as a JSON Merge Patch
+// (RFC 7396) or a JSON Patch (RFC 6902), as its Content-Type says. It returns
+// a function that applies the patch to the JSON form of a stored record of T
+// and decodes the result into a new record, ready for the partial-update
+// methods of the database client to validate and compare.
+func readPatch[T any](ctx echo.Context) (func(current *T) (*T, error), error) {
+	ctx.Response().Header().Set("Accept-Patch", mergePatch+", "+jsonPatch)
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != mergePatch && mediaType != jsonPatch {
+		return nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, "PATCH takes "+mergePatch+" or "+jsonPatch)
+	}
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch func(document []byte) ([]byte, error)
+	if mediaType == mergePatch {
+		var object map[string]interface{}
+		if err := json.Unmarshal(body, &object); err != nil || object == nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "a merge patch must be a JSON object")
+		}
+		patch = func(document []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(document, body)
+		}
+	} else {
+		operations, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "a JSON patch must be an array of operations")
+		}
+		patch = operations.Apply
+	}
+
+	return func(current *T) (*T, error) {
+		document, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+
+		document, err = patch(document)
+		if err != nil {
+			return nil, &dberrors.ValidationError{Field: "patch", Message: err.Error()}
+		}
+
+		patched := new(T)
+		if err := json.Unmarshal(document, patched); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return nil, &dberrors.ValidationError{Field: typeErr.Field, Message: "cannot be a " + typeErr.Value}
+			}
+			return nil, &dberrors.ValidationError{Field: "patch", Message: err.Error()}
+		}
+		return patched, nil
+	}, nil
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllPets godoc
+// @Summary Get all pets of a customer
+// @Description Get the pets owned by a customer with optional pagination
+// @Tags pets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.Pet]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets [get]
+func (s *EchoServer) GetAllPets(ctx echo.Context) error {
+	customerid := ctx.Param("id")
+
+	pets, err := s.DB.GetAllPets(ctx.Request().Context(), customerid, pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, pets)
+}
+
+// GetPetById godoc
+// @Summary Get pet by ID
+// @Description Get a single pet of a customer by its ID
+// @Tags pets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pet_id path string true "Pet ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.Pet
+// @Header 200 {string} ETag "Version of the pet"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets/{pet_id} [get]
+func (s *EchoServer) GetPetById(ctx echo.Context) error {
+	pet, err := s.DB.GetPetById(ctx.Request().Context(), ctx.Param("id"), ctx.Param("pet_id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, pet.Version, pet)
+}
+
+// AddPet godoc
+// @Summary Add a new pet
+// @Description Register a new pet for a customer
+// @Tags pets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pet body models.Pet true "Pet to add"
+// @Success 201 {object} models.Pet
+// @Header 201 {string} ETag "Version of the pet"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets [post]
+func (s *EchoServer) AddPet(ctx echo.Context) error {
+	pet := new(models.Pet)
+
+	if err := ctx.Bind(pet); err != nil {
+		return err
+	}
+
+	customerID, errUUID := dberrors.ParseID(ctx.Param("id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	pet.CustomerID = customerID
+
+	pet, err := s.DB.AddPet(ctx.Request().Context(), pet)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, pet.Version, pet)
+}
+
+// UpdatePet godoc
+// @Summary Update an existing pet
+// @Description Update a pet's details by providing the owner and pet IDs
+// @Tags pets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pet_id path string true "Pet ID"
+// @Param pet body models.Pet true "Updated pet data"
+// @Param If-Match header string true "ETag of the pet as last read, or * for any version"
+// @Success 201 {object} models.Pet
+// @Header 201 {string} ETag "Version of the pet"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The pet has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets/{pet_id} [put]
+func (s *EchoServer) UpdatePet(ctx echo.Context) error {
+	pet := new(models.Pet)
+
+	if err := ctx.Bind(pet); err != nil {
+		return err
+	}
+
+	customerID, errUUID := dberrors.ParseID(ctx.Param("id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	ID, errUUID := dberrors.ParseID(ctx.Param("pet_id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	if pet.PetID != uuid.Nil && pet.PetID != ID {
+		return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
+	}
+	pet.PetID = ID
+	pet.CustomerID = customerID
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+	pet.Version = version
+
+	pet, err = s.DB.UpdatePet(ctx.Request().Context(), pet)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, pet.Version, pet)
+}
+
+// PatchPet godoc
+// @Summary Patch a pet
+// @Description Change only some fields of a pet with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Fields the patch leaves alone keep their stored values
+// @Tags pets
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pet_id path string true "Pet ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string true "ETag of the pet as last read, or * for any version"
+// @Success 200 {object} models.Pet
+// @Header 200 {string} ETag "Version of the pet"
+// @Failure 400 {object} server.ErrorResponse "Malformed patch"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The pet has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "The patch cannot be applied or the result is invalid"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets/{pet_id} [patch]
+func (s *EchoServer) PatchPet(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	apply, err := readPatch[models.Pet](ctx)
+	if err != nil {
+		return err
+	}
+
+	pet, err := s.DB.PatchPet(ctx.Request().Context(), ctx.Param("id"), ctx.Param("pet_id"), version, apply)
+	if err != nil {
+		return err
+	}
+
+	return writeVersioned(ctx, http.StatusOK, pet.Version, pet)
+}
+
+// DeletePet godoc
+// @Summary Delete a pet
+// @Description Delete a customer's pet by its ID
+// @Tags pets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param pet_id path string true "Pet ID"
+// @Param If-Match header string true "ETag of the pet as last read, or * for any version"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The pet has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /customers/{id}/pets/{pet_id} [delete]
+func (s *EchoServer) DeletePet(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeletePet(ctx.Request().Context(), ctx.Param("id"), ctx.Param("pet_id"), version)
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record deleted successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
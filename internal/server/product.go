@@ -2,36 +2,73 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/johnifegwu/go-microservices/internal/transfer"
 	"github.com/labstack/echo/v4"
 )
 
-// SearchProducts godoc
-// @Summary Search products
-// @Description Search for products by a search term with optional pagination
+// SearchProductsByTerm godoc
+// @Summary Search products by path term
+// @Description Search for products by a search term with optional pagination. Kept for existing clients; /products/search ranks and highlights results
 // @Tags products
 // @Accept  json
 // @Produce  json
 // @Param searchterm path string true "Search term"
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Product
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as vendor_id=<id> or price>=10; repeat to combine" collectionFormat(multi)
+// @Success 200 {object} models.Page[models.Product]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/search/{searchterm} [get]
-func (s *EchoServer) SearchProducts(ctx echo.Context) error {
+func (s *EchoServer) SearchProductsByTerm(ctx echo.Context) error {
 	searchterm := ctx.Param("searchterm")
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
 
-	products, err := s.DB.SearchProducts(ctx.Request().Context(), searchterm, pageindex, pagesize)
+	hits, err := s.DB.SearchProducts(ctx.Request().Context(), searchterm, pageRequest(ctx))
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
-	return ctx.JSON(http.StatusOK, products)
+	products := &models.Page[models.Product]{Items: make([]models.Product, len(hits.Items)), Total: hits.Total}
+	for i, hit := range hits.Items {
+		products.Items[i] = hit.Item
+	}
+	return writePage(ctx, products)
+}
+
+// SearchProducts godoc
+// @Summary Search products
+// @Description Full-text search over product names with a fuzzy fallback for typos. Results are ranked, highlighted and can be filtered, e.g. by vendor_id or a price range
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search text"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as vendor_id=<id> or price>=10; repeat to combine" collectionFormat(multi)
+// @Success 200 {object} models.Page[models.SearchHit[models.Product]]
+// @Failure 400 {object} server.ErrorResponse "Missing search text or invalid filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/search [get]
+func (s *EchoServer) SearchProducts(ctx echo.Context) error {
+	hits, err := s.DB.SearchProducts(ctx.Request().Context(), ctx.QueryParam("q"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, hits)
 }
 
 // GetAllProducts godoc
@@ -42,16 +79,25 @@ func (s *EchoServer) SearchProducts(ctx echo.Context) error {
 // @Produce  json
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Product
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Param include_deleted query bool false "Include soft-deleted records"
+// @Success 200 {object} models.Page[models.Product]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products [get]
 func (s *EchoServer) GetAllProducts(ctx echo.Context) error {
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
-	products, err := s.DB.GetAllProducts(ctx.Request().Context(), pageindex, pagesize)
+	includeDeleted, _ := strconv.ParseBool(ctx.QueryParam("include_deleted"))
+	products, err := s.DB.GetAllProducts(ctx.Request().Context(), pageRequest(ctx), includeDeleted)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, products)
+	return writePage(ctx, products)
 }
 
 // GetProductById godoc
@@ -61,15 +107,22 @@ func (s *EchoServer) GetAllProducts(ctx echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
 // @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/{id} [get]
 func (s *EchoServer) GetProductById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	products, err := s.DB.GetProductById(ctx.Request().Context(), id)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, products)
+	return writeVersioned(ctx, http.StatusOK, products.Version, products)
 }
 
 // GetAllProductsByVendor godoc
@@ -81,17 +134,24 @@ func (s *EchoServer) GetProductById(ctx echo.Context) error {
 // @Param id path string true "Vendor ID"
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Product
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.Product]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/vendor/{id} [get]
 func (s *EchoServer) GetAllProductsByVendor(ctx echo.Context) error {
 	vendorid := ctx.Param("id")
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
-	products, err := s.DB.GetAllProductsByVendor(ctx.Request().Context(), vendorid, pageindex, pagesize)
+	products, err := s.DB.GetAllProductsByVendor(ctx.Request().Context(), vendorid, pageRequest(ctx))
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, products)
+	return writePage(ctx, products)
 }
 
 // AddProduct godoc
@@ -102,87 +162,163 @@ func (s *EchoServer) GetAllProductsByVendor(ctx echo.Context) error {
 // @Produce  json
 // @Param product body models.Product true "Product to add"
 // @Success 201 {object} models.Product
-// @Failure 409 {object} dberrors.ConflictError
-// @Failure 415 {object} string "Unsupported Media Type"
+// @Header 201 {string} ETag "Version of the product"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unknown vendor"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products [post]
 func (s *EchoServer) AddProduct(ctx echo.Context) error {
 	product := new(models.Product)
 
 	if err := ctx.Bind(product); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
 	product, err := s.DB.AddProduct(ctx.Request().Context(), product)
 
 	if err != nil {
-		switch err.(type) {
-		case *dberrors.ConflictError:
-			return ctx.JSON(http.StatusConflict, err)
-		default:
-			return ctx.JSON(http.StatusInternalServerError, err)
-		}
+		return err
 	}
 
-	return ctx.JSON(http.StatusCreated, product)
+	return writeVersioned(ctx, http.StatusCreated, product.Version, product)
 }
 
 // UpdateProduct godoc
 // @Summary Update an existing product
-// @Description Update a product's details by providing its ID
+// @Description Replace a product's details. Stock and the reorder threshold are left unchanged; they are set under /products/{id}/stock. The ID on the path wins; the body may repeat it or leave it out. The former PUT /products that took the ID from the body still works but is deprecated
 // @Tags products
 // @Accept  json
 // @Produce  json
-// @Param product_id path string true "Product ID"
+// @Param id path string true "Product ID"
 // @Param product body models.Product true "Updated product data"
+// @Param If-Match header string true "ETag of the product as last read, or * for any version"
 // @Success 201 {object} models.Product
-// @Failure 400 {object} string "Bad Request"
-// @Failure 415 {object} string "Unsupported Media Type"
-// @Router /products/{product_id} [put]
+// @Header 201 {string} ETag "Version of the product"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The product has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unknown vendor"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id} [put]
 func (s *EchoServer) UpdateProduct(ctx echo.Context) error {
 	product := new(models.Product)
 
 	if err := ctx.Bind(product); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
-	ID, errUUID := uuid.Parse(ctx.Param("product_id"))
-	if errUUID == nil {
-		if ID != product.ProductID {
-			return ctx.JSON(http.StatusBadRequest, "id on path doesn't match id on body")
+	// The legacy route has no ID on the path and takes the one in the body
+	if id := ctx.Param("id"); id != "" {
+		ID, err := dberrors.ParseID(id)
+		if err != nil {
+			return err
+		}
+		if product.ProductID != uuid.Nil && product.ProductID != ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
 		}
+		product.ProductID = ID
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
 	}
+	product.Version = version
 
-	product, err := s.DB.UpdateProduct(ctx.Request().Context(), product)
+	product, err = s.DB.UpdateProduct(ctx.Request().Context(), product)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
-	return ctx.JSON(http.StatusCreated, product)
+	return writeVersioned(ctx, http.StatusCreated, product.Version, product)
+}
+
+// PatchProduct godoc
+// @Summary Patch a product
+// @Description Change only some fields of a product with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Fields the patch leaves alone keep their stored values
+// @Tags products
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string true "ETag of the product as last read, or * for any version"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 400 {object} server.ErrorResponse "Malformed patch"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The product has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "The patch cannot be applied, the result is invalid or the vendor is unknown"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id} [patch]
+func (s *EchoServer) PatchProduct(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	apply, err := readPatch[models.Product](ctx)
+	if err != nil {
+		return err
+	}
+
+	product, err := s.DB.PatchProduct(ctx.Request().Context(), ctx.Param("id"), version, apply)
+	if err != nil {
+		return err
+	}
+
+	return writeVersioned(ctx, http.StatusOK, product.Version, product)
 }
 
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete a product from the database by its ID
+// @Description Soft-delete a product by its ID. The record is hidden but can be restored. The former DELETE /products?id= still works but is deprecated
 // @Tags products
 // @Accept  json
 // @Produce  json
-// @Param id query string true "Product ID"
+// @Param id path string true "Product ID"
+// @Param If-Match header string true "ETag of the product as last read, or * for any version"
 // @Success 200 {object} server.Response
-// @Failure 500 {object} dberrors.ZeroRowsAffectedError
-// @Router /products [delete]
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The product has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id} [delete]
 func (s *EchoServer) DeleteProduct(ctx echo.Context) error {
-	var productId = ctx.QueryParam("id")
+	var productId = resourceID(ctx)
 
-	rowsaffected, err := s.DB.DeleteProduct(ctx.Request().Context(), productId)
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteProduct(ctx.Request().Context(), productId, version)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
 	if rowsaffected < 1 {
-		dberr := new(dberrors.ZeroRowsAffectedError)
-		return ctx.JSON(http.StatusInternalServerError, dberr.Error())
+		return &dberrors.ZeroRowsAffectedError{}
 	}
 
 	response := server.Response{
@@ -191,3 +327,135 @@ func (s *EchoServer) DeleteProduct(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Bring back a soft-deleted product
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/restore [post]
+func (s *EchoServer) RestoreProduct(ctx echo.Context) error {
+	product, err := s.DB.RestoreProduct(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, product.Version, product)
+}
+
+// PurgeProduct godoc
+// @Summary Permanently delete a product
+// @Description Remove a soft-deleted product for good. Refused while orders or purchase orders still reference it. Needs products:purge, which only the admin role holds by default
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/purge [delete]
+func (s *EchoServer) PurgeProduct(ctx echo.Context) error {
+	rowsaffected, err := s.DB.PurgeProduct(ctx.Request().Context(), ctx.Param("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record purged successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// BatchProducts godoc
+// @Summary Create, update and delete products in one batch
+// @Description Apply many writes in a single transaction. In atomic mode (the default) any failure rolls back the whole batch; in partial mode successful writes are kept. Each write's outcome is reported with its own status. Creates and updates get the same vendor and name conflict checks as single writes
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param batch body models.Batch[models.Product] true "Writes to apply"
+// @Success 200 {object} server.BatchResponse "Every write succeeded"
+// @Success 207 {object} server.BatchResponse "At least one write failed"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products:batch [post]
+func (s *EchoServer) BatchProducts(ctx echo.Context) error {
+	batch := new(models.Batch[models.Product])
+
+	if err := ctx.Bind(batch); err != nil {
+		return err
+	}
+
+	results, err := s.DB.BatchProducts(ctx.Request().Context(), batch)
+	if err != nil {
+		return err
+	}
+	return writeBatch(ctx, batch.Mode, results)
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Stream every live product as a CSV or JSON lines download. CSV files start with a header row of column names, and cells a spreadsheet would run as a formula are prefixed with ' (importing the file removes it again); JSON lines hold one product per line as the API returns it. Filters narrow the export as they do the listing
+// @Tags products
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "File format" Enums(csv, jsonl) default(csv)
+// @Param filter query []string false "Filters of the form field<operator>value, e.g. name~acme" collectionFormat(multi)
+// @Success 200 {file} file
+// @Failure 400 {object} server.ErrorResponse "Invalid format or filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/export [get]
+func (s *EchoServer) ExportProducts(ctx echo.Context) error {
+	return writeExport(ctx, "products", transfer.Products, s.DB.ExportProducts)
+}
+
+// ImportProducts godoc
+// @Summary Import products
+// @Description Create and update products from a CSV or JSON lines file, as exported. Rows without product_id create a product, rows with one update the columns they give, leaving the rest unchanged. Headers that differ from the column names can be mapped with map=header=column. In atomic mode (the default) any failing row rolls back the whole import; in partial mode the good rows are kept. A dry run applies every row and then rolls back. Each row is reported by its line in the file. Rows may name their vendor in a vendor column instead of giving vendor_id; quantity_on_hand is read as opening stock for new products only
+// @Tags products
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Produce  json
+// @Param file body string true "Rows to import"
+// @Param format query string false "File format, taken from the Content-Type when omitted" Enums(csv, jsonl)
+// @Param map query []string false "Header mappings of the form header=column" collectionFormat(multi)
+// @Param mode query string false "Import mode" Enums(atomic, partial) default(atomic)
+// @Param dry_run query bool false "Validate and report without keeping any change"
+// @Success 200 {object} server.ImportResponse "Every row succeeded"
+// @Success 207 {object} server.ImportResponse "At least one row failed"
+// @Failure 400 {object} server.ErrorResponse "Invalid format, map or dry_run"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unreadable header, empty file or too many rows"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/import [post]
+func (s *EchoServer) ImportProducts(ctx echo.Context) error {
+	return readImport(ctx, transfer.Products, s.DB.ImportProducts)
+}
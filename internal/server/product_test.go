@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/config"
+	database "github.com/johnifegwu/go-microservices/internal/infrastructure"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Patching a product onto a vendor that does not exist is refused with 422
+// before anything is written.
+func TestPatchProductMissingVendor(t *testing.T) {
+	productID, vendorID, missing := uuid.New(), uuid.New(), uuid.New()
+	db := newScriptedDB(t, func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, `"wisdom"."products"`) && strings.Contains(query, "FOR UPDATE"):
+			return []string{"version"}, [][]driver.Value{{int64(3)}}
+		case strings.Contains(query, `"wisdom"."products"`):
+			return []string{"product_id", "name", "price", "vendor_id", "quantity_on_hand", "reorder_threshold", "version", "deleted_at"},
+				[][]driver.Value{{productID.String(), "Kibble", 12.5, vendorID.String(), int64(4), int64(1), int64(3), nil}}
+		}
+		// No vendor has the new ID
+		return []string{"vendor_id"}, nil
+	})
+
+	cfg := config.Default()
+	cfg.Features.Auth = false
+	cfg.Features.Swagger = false
+	srv, err := NewEchoServer(cfg, database.Client{DB: db.gorm})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"vendor_id":"` + missing.String() + `"}`
+	request := httptest.NewRequest(http.MethodPatch, "/products/"+productID.String(), strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	request.Header.Set("If-Match", `"3"`)
+	response := httptest.NewRecorder()
+	srv.(*EchoServer).echo.ServeHTTP(response, request)
+
+	if response.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body %s", response.Code, http.StatusUnprocessableEntity, response.Body)
+	}
+	if !strings.Contains(response.Body.String(), missing.String()) {
+		t.Errorf("body %s does not name vendor %s", response.Body, missing)
+	}
+	for _, query := range db.queries() {
+		if strings.HasPrefix(query, "UPDATE") {
+			t.Errorf("product was written: %s", query)
+		}
+	}
+}
+
+// scriptedDB is a database/sql driver that answers every query with the
+// columns and rows respond returns for it, and remembers the queries.
+type scriptedDB struct {
+	gorm    *gorm.DB
+	respond func(query string) ([]string, [][]driver.Value)
+
+	mu   sync.Mutex
+	seen []string
+}
+
+func newScriptedDB(t *testing.T, respond func(query string) ([]string, [][]driver.Value)) *scriptedDB {
+	t.Helper()
+	db := &scriptedDB{respond: respond}
+	dialector := postgres.New(postgres.Config{Conn: sql.OpenDB(db)})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.gorm = gormDB
+	return db
+}
+
+func (db *scriptedDB) queries() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.seen...)
+}
+
+func (db *scriptedDB) record(query string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.seen = append(db.seen, query)
+}
+
+func (db *scriptedDB) Connect(context.Context) (driver.Conn, error) { return scriptedConn{db}, nil }
+func (db *scriptedDB) Driver() driver.Driver                        { return nil }
+
+type scriptedConn struct{ db *scriptedDB }
+
+func (c scriptedConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c scriptedConn) Close() error                        { return nil }
+func (c scriptedConn) Begin() (driver.Tx, error)           { c.db.record("BEGIN"); return scriptedTx{c.db}, nil }
+
+func (c scriptedConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query)
+	columns, rows := c.db.respond(query)
+	return &scriptedRows{columns: columns, rows: rows}, nil
+}
+
+func (c scriptedConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type scriptedTx struct{ db *scriptedDB }
+
+func (tx scriptedTx) Commit() error   { tx.db.record("COMMIT"); return nil }
+func (tx scriptedTx) Rollback() error { tx.db.record("ROLLBACK"); return nil }
+
+type scriptedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
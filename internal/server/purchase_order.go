@@ -0,0 +1,182 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllPurchaseOrders godoc
+// @Summary Get all purchase orders of a vendor
+// @Description Get a vendor's purchase orders with optional filtering by status, and pagination
+// @Tags purchase-orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param status query string false "Status for filtering"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.PurchaseOrder]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purchase-orders [get]
+func (s *EchoServer) GetAllPurchaseOrders(ctx echo.Context) error {
+	vendorid := ctx.Param("id")
+	status := ctx.QueryParam("status")
+
+	purchaseOrders, err := s.DB.GetAllPurchaseOrders(ctx.Request().Context(), vendorid, status, pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, purchaseOrders)
+}
+
+// GetPurchaseOrderById godoc
+// @Summary Get purchase order by ID
+// @Description Get a single purchase order of a vendor by its ID
+// @Tags purchase-orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param po_id path string true "Purchase order ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.PurchaseOrder
+// @Header 200 {string} ETag "Version of the purchase order"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purchase-orders/{po_id} [get]
+func (s *EchoServer) GetPurchaseOrderById(ctx echo.Context) error {
+	purchaseOrder, err := s.DB.GetPurchaseOrderById(ctx.Request().Context(), ctx.Param("id"), ctx.Param("po_id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, purchaseOrder.Version, purchaseOrder)
+}
+
+// AddPurchaseOrder godoc
+// @Summary Raise a purchase order
+// @Description Raise a draft purchase order against a vendor for products it supplies
+// @Tags purchase-orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param purchase_order body models.PurchaseOrder true "Purchase order to raise"
+// @Success 201 {object} models.PurchaseOrder
+// @Header 201 {string} ETag "Version of the purchase order"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purchase-orders [post]
+func (s *EchoServer) AddPurchaseOrder(ctx echo.Context) error {
+	purchaseOrder := new(models.PurchaseOrder)
+
+	if err := ctx.Bind(purchaseOrder); err != nil {
+		return err
+	}
+
+	vendorID, errUUID := dberrors.ParseID(ctx.Param("id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	purchaseOrder.VendorID = vendorID
+
+	purchaseOrder, err := s.DB.AddPurchaseOrder(ctx.Request().Context(), purchaseOrder)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, purchaseOrder.Version, purchaseOrder)
+}
+
+// UpdatePurchaseOrderStatus godoc
+// @Summary Change purchase order status
+// @Description Send or cancel a purchase order. Illegal transitions are rejected; receipt statuses are set by receiving goods
+// @Tags purchase-orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param po_id path string true "Purchase order ID"
+// @Param status body server.StatusRequest true "New status"
+// @Param If-Match header string true "ETag of the purchase order as last read, or * for any version"
+// @Success 200 {object} models.PurchaseOrder
+// @Header 200 {string} ETag "Version of the purchase order"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The purchase order has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purchase-orders/{po_id}/status [put]
+func (s *EchoServer) UpdatePurchaseOrderStatus(ctx echo.Context) error {
+	request := new(server.StatusRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		return err
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	purchaseOrder, err := s.DB.UpdatePurchaseOrderStatus(ctx.Request().Context(), ctx.Param("id"), ctx.Param("po_id"), request.Status, version)
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, purchaseOrder.Version, purchaseOrder)
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary Receive goods against a purchase order
+// @Description Book delivered quantities against a sent purchase order and add them to stock
+// @Tags purchase-orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param po_id path string true "Purchase order ID"
+// @Param receipts body []models.PurchaseOrderReceipt true "Delivered quantities per product"
+// @Success 200 {object} models.PurchaseOrder
+// @Header 200 {string} ETag "Version of the purchase order"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purchase-orders/{po_id}/receipts [post]
+func (s *EchoServer) ReceivePurchaseOrder(ctx echo.Context) error {
+	var receipts []models.PurchaseOrderReceipt
+
+	if err := ctx.Bind(&receipts); err != nil {
+		return err
+	}
+
+	purchaseOrder, err := s.DB.ReceivePurchaseOrder(ctx.Request().Context(), ctx.Param("id"), ctx.Param("po_id"), receipts)
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, purchaseOrder.Version, purchaseOrder)
+}
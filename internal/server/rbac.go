@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/johnifegwu/go-microservices/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// require lets a request through only when its principal holds every one of
+// permissions, such as products:write, and answers 403 otherwise. With
+// authentication off there is no principal and nothing is checked.
+func (s *EchoServer) require(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !s.config.Features.Auth {
+				return next(ctx)
+			}
+
+			request := ctx.Request()
+			principal, ok := auth.PrincipalFrom(request.Context())
+			if !ok {
+				ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer`)
+				return echo.NewHTTPError(http.StatusUnauthorized, "a bearer token or API key is required")
+			}
+
+			granted, err := s.permissions(request.Context(), principal)
+			if err != nil {
+				return err
+			}
+			for _, permission := range permissions {
+				if !auth.Allows(granted, permission) {
+					return echo.NewHTTPError(http.StatusForbidden, "missing permission "+permission)
+				}
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// permissions are what principal holds: those an API key was issued with,
+// everything for the auth config's admin_subjects, or else whatever the
+// subject's roles grant.
+func (s *EchoServer) permissions(ctx context.Context, principal *auth.Principal) ([]string, error) {
+	if principal.Permissions != nil {
+		return principal.Permissions, nil
+	}
+	if slices.Contains(s.config.Auth.AdminSubjects, principal.Subject) {
+		return []string{"*:*"}, nil
+	}
+	return s.DB.GetPermissions(ctx, principal.Subject)
+}
+
+// grantable answers 403 unless the caller holds every one of permissions,
+// so that nobody can hand out more than they have, whether through an API
+// key or a role. With authentication off there is no principal and nothing
+// is checked.
+func (s *EchoServer) grantable(ctx context.Context, permissions []string) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	granted, err := s.permissions(ctx, principal)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if !auth.Allows(granted, permission) {
+			return echo.NewHTTPError(http.StatusForbidden, "cannot grant permission "+permission+" without holding it")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllRoles godoc
+// @Summary Get all roles
+// @Description Retrieve the roles and the permissions each grants, with optional pagination
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as name~desk; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -name"
+// @Success 200 {object} models.Page[models.Role]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles [get]
+func (s *EchoServer) GetAllRoles(ctx echo.Context) error {
+	roles, err := s.DB.GetAllRoles(ctx.Request().Context(), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, roles)
+}
+
+// GetRoleById godoc
+// @Summary Get role by ID
+// @Description Retrieve a role by its ID
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.Role
+// @Header 200 {string} ETag "Version of the role"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id} [get]
+func (s *EchoServer) GetRoleById(ctx echo.Context) error {
+	role, err := s.DB.GetRoleById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, role.Version, role)
+}
+
+// AddRole godoc
+// @Summary Add a new role
+// @Description Create a role granting permissions of the form resource:action, where action is read, write, delete or purge. Either half may be * to match any. Callers can only grant permissions they hold themselves
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param role body models.Role true "Role to add"
+// @Success 201 {object} models.Role
+// @Header 201 {string} ETag "Version of the role"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission, or granting one the caller does not hold"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles [post]
+func (s *EchoServer) AddRole(ctx echo.Context) error {
+	role := new(models.Role)
+
+	if err := ctx.Bind(role); err != nil {
+		return err
+	}
+
+	// Otherwise a caller could write a role granting *:* and assign it to itself
+	if err := s.grantable(ctx.Request().Context(), role.Permissions); err != nil {
+		return err
+	}
+
+	role, err := s.DB.AddRole(ctx.Request().Context(), role)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, role.Version, role)
+}
+
+// UpdateRole godoc
+// @Summary Update an existing role
+// @Description Replace a role's name, description and permissions. Subjects holding the role get the new permissions on their next request. Callers can only grant permissions they hold themselves
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param role body models.Role true "Updated role data"
+// @Param If-Match header string true "ETag of the role as last read, or * for any version"
+// @Success 201 {object} models.Role
+// @Header 201 {string} ETag "Version of the role"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission, or granting one the caller does not hold"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The role has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id} [put]
+func (s *EchoServer) UpdateRole(ctx echo.Context) error {
+	role := new(models.Role)
+
+	if err := ctx.Bind(role); err != nil {
+		return err
+	}
+
+	ID, err := dberrors.ParseID(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	if role.RoleID != uuid.Nil && role.RoleID != ID {
+		return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
+	}
+	role.RoleID = ID
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+	role.Version = version
+
+	if err := s.grantable(ctx.Request().Context(), role.Permissions); err != nil {
+		return err
+	}
+
+	role, err = s.DB.UpdateRole(ctx.Request().Context(), role)
+
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusCreated, role.Version, role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a role by its ID. Roles still assigned to someone are refused
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param If-Match header string true "ETag of the role as last read, or * for any version"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The role has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id} [delete]
+func (s *EchoServer) DeleteRole(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteRole(ctx.Request().Context(), ctx.Param("id"), version)
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record deleted successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// GetRoleAssignments godoc
+// @Summary Get the subjects holding a role
+// @Description List the token subjects a role is assigned to, with optional pagination
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as subject~@example.com; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -created_at"
+// @Success 200 {object} models.Page[models.RoleAssignment]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id}/assignments [get]
+func (s *EchoServer) GetRoleAssignments(ctx echo.Context) error {
+	assignments, err := s.DB.GetRoleAssignments(ctx.Request().Context(), ctx.Param("id"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, assignments)
+}
+
+// AddRoleAssignment godoc
+// @Summary Assign a role
+// @Description Give a role to the caller whose bearer tokens carry subject as their sub claim. Callers can only assign roles whose permissions they hold themselves
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param assignment body models.RoleAssignment true "Subject to assign the role to"
+// @Success 201 {object} models.RoleAssignment
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission, or assigning one the caller does not hold"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id}/assignments [post]
+func (s *EchoServer) AddRoleAssignment(ctx echo.Context) error {
+	assignment := new(models.RoleAssignment)
+
+	if err := ctx.Bind(assignment); err != nil {
+		return err
+	}
+
+	roleID, errUUID := dberrors.ParseID(ctx.Param("id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	assignment.RoleID = roleID
+
+	role, err := s.DB.GetRoleById(ctx.Request().Context(), roleID.String())
+	if err != nil {
+		return err
+	}
+	if err := s.grantable(ctx.Request().Context(), role.Permissions); err != nil {
+		return err
+	}
+
+	assignment, err = s.DB.AddRoleAssignment(ctx.Request().Context(), assignment)
+
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, assignment)
+}
+
+// DeleteRoleAssignment godoc
+// @Summary Unassign a role
+// @Description Take a role away from a subject
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Role ID"
+// @Param subject path string true "Subject holding the role"
+// @Success 200 {object} server.Response
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /roles/{id}/assignments/{subject} [delete]
+func (s *EchoServer) DeleteRoleAssignment(ctx echo.Context) error {
+	// Subjects such as auth0|123 or URLs arrive percent-encoded
+	subject, err := url.PathUnescape(ctx.Param("subject"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "subject is not a valid path segment")
+	}
+
+	rowsaffected, err := s.DB.DeleteRoleAssignment(ctx.Request().Context(), ctx.Param("id"), subject)
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record deleted successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
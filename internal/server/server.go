@@ -1,19 +1,26 @@
 package server
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/johnifegwu/go-microservices/docs" // Import the generated docs
+	"github.com/johnifegwu/go-microservices/internal/auth"
+	"github.com/johnifegwu/go-microservices/internal/config"
 	database "github.com/johnifegwu/go-microservices/internal/infrastructure"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger" // Import echo-swagger middleware
 )
 
 type Server interface {
 	Start() error
+	Shutdown(ctx context.Context) error
 	Readiness(ctx echo.Context) error
 	Liveness(ctx echo.Context) error
 
@@ -21,39 +28,126 @@ type Server interface {
 	GetCustomerById(ctx echo.Context) error
 	AddCustomer(ctx echo.Context) error
 	UpdateCustomer(ctx echo.Context) error
+	PatchCustomer(ctx echo.Context) error
 	DeleteCustomer(ctx echo.Context) error
+	BatchCustomers(ctx echo.Context) error
+	ExportCustomers(ctx echo.Context) error
+	ImportCustomers(ctx echo.Context) error
+	RestoreCustomer(ctx echo.Context) error
+	PurgeCustomer(ctx echo.Context) error
+
+	GetAllPets(ctx echo.Context) error
+	GetPetById(ctx echo.Context) error
+	AddPet(ctx echo.Context) error
+	UpdatePet(ctx echo.Context) error
+	PatchPet(ctx echo.Context) error
+	DeletePet(ctx echo.Context) error
 
 	GetAllProducts(ctx echo.Context) error
 	SearchProducts(ctx echo.Context) error
+	SearchProductsByTerm(ctx echo.Context) error
 	GetProductById(ctx echo.Context) error
 	GetAllProductsByVendor(ctx echo.Context) error
 	AddProduct(ctx echo.Context) error
 	UpdateProduct(ctx echo.Context) error
+	PatchProduct(ctx echo.Context) error
 	DeleteProduct(ctx echo.Context) error
+	BatchProducts(ctx echo.Context) error
+	ExportProducts(ctx echo.Context) error
+	ImportProducts(ctx echo.Context) error
+	RestoreProduct(ctx echo.Context) error
+	PurgeProduct(ctx echo.Context) error
+
+	GetProductStock(ctx echo.Context) error
+	SetReorderThreshold(ctx echo.Context) error
+	GetStockMovements(ctx echo.Context) error
+	RecordStockMovement(ctx echo.Context) error
+	GetLowStockProductsByVendor(ctx echo.Context) error
 
 	GetAllServices(ctx echo.Context) error
+	SearchServices(ctx echo.Context) error
 	GetServiceById(ctx echo.Context) error
 	AddService(ctx echo.Context) error
 	UpdateService(ctx echo.Context) error
+	PatchService(ctx echo.Context) error
 	DeleteService(ctx echo.Context) error
+	BatchServices(ctx echo.Context) error
+	ExportServices(ctx echo.Context) error
+	ImportServices(ctx echo.Context) error
+	RestoreService(ctx echo.Context) error
+	PurgeService(ctx echo.Context) error
 
 	GetAllVendors(ctx echo.Context) error
+	SearchVendors(ctx echo.Context) error
 	GetVendorById(ctx echo.Context) error
 	AddVendor(ctx echo.Context) error
 	UpdateVendor(ctx echo.Context) error
+	PatchVendor(ctx echo.Context) error
 	DeleteVendor(ctx echo.Context) error
+	BatchVendors(ctx echo.Context) error
+	ExportVendors(ctx echo.Context) error
+	ImportVendors(ctx echo.Context) error
+	RestoreVendor(ctx echo.Context) error
+	PurgeVendor(ctx echo.Context) error
+
+	GetAllPurchaseOrders(ctx echo.Context) error
+	GetPurchaseOrderById(ctx echo.Context) error
+	AddPurchaseOrder(ctx echo.Context) error
+	UpdatePurchaseOrderStatus(ctx echo.Context) error
+	ReceivePurchaseOrder(ctx echo.Context) error
+
+	GetAllOrders(ctx echo.Context) error
+	GetOrderById(ctx echo.Context) error
+	AddOrder(ctx echo.Context) error
+	DeleteOrder(ctx echo.Context) error
+
+	GetAllAppointments(ctx echo.Context) error
+	GetAvailability(ctx echo.Context) error
+	GetAppointmentById(ctx echo.Context) error
+	AddAppointment(ctx echo.Context) error
+	DeleteAppointment(ctx echo.Context) error
+
+	GetAllRoles(ctx echo.Context) error
+	GetRoleById(ctx echo.Context) error
+	AddRole(ctx echo.Context) error
+	UpdateRole(ctx echo.Context) error
+	DeleteRole(ctx echo.Context) error
+	GetRoleAssignments(ctx echo.Context) error
+	AddRoleAssignment(ctx echo.Context) error
+	DeleteRoleAssignment(ctx echo.Context) error
+
+	GetAllAPIKeys(ctx echo.Context) error
+	GetAPIKeyById(ctx echo.Context) error
+	AddAPIKey(ctx echo.Context) error
+	RevokeAPIKey(ctx echo.Context) error
+
+	GetAuditEntries(ctx echo.Context) error
 }
 
 // @title Echo Server API
 // @version 1.0
-// @description This is a sample server for managing customers, products, services, and vendors.
+// @description This is a sample server for managing customers, products, services, vendors and orders.
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer JWT from the configured issuer, as "Bearer <token>"
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued through /admin/api-keys
 
 // EchoServer represents the server
 type EchoServer struct {
-	echo *echo.Echo
-	DB   database.DatabaseClient
+	echo   *echo.Echo
+	DB     database.DatabaseClient
+	config *config.Config
+
+	// shuttingDown fails readiness once Shutdown has been called
+	shuttingDown atomic.Bool
+	// stop ends background work such as refreshing signing keys
+	stop context.CancelFunc
 }
 
 // @Summary Readiness probe
@@ -63,8 +157,13 @@ type EchoServer struct {
 // @Produce  json
 // @Success 200 {object} models.Health
 // @Failure 500 {object} models.Health
+// @Failure 503 {object} models.Health "Shutting down"
 // @Router /readiness [get]
 func (s *EchoServer) Readiness(ctx echo.Context) error {
+	if s.shuttingDown.Load() {
+		return ctx.JSON(http.StatusServiceUnavailable, models.Health{Status: "Shutting down"})
+	}
+
 	ready := s.DB.Ready()
 	if ready {
 		return ctx.JSON(http.StatusOK, models.Health{Status: "OK"})
@@ -83,60 +182,206 @@ func (s *EchoServer) Liveness(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, models.Health{Status: "OK"})
 }
 
-func NewEchoServer(db database.DatabaseClient) Server {
+// NewEchoServer builds the server and its routes. With the auth feature on
+// it loads the issuer's signing keys first and fails if they cannot be read;
+// API keys are accepted alongside bearer tokens.
+func NewEchoServer(cfg *config.Config, db database.DatabaseClient) (Server, error) {
+	background, stop := context.WithCancel(context.Background())
 	server := &EchoServer{
-		echo: echo.New(),
-		DB:   db,
+		echo:   echo.New(),
+		DB:     db,
+		config: cfg,
+		stop:   stop,
+	}
+	server.echo.HTTPErrorHandler = server.errorHandler
+	server.echo.Binder = &binder{}
+	server.echo.Use(middleware.RequestID())
+	// A panic fails its request with 500 instead of dropping the connection
+	server.echo.Use(middleware.Recover())
+
+	if cfg.Features.Auth {
+		verifier, err := auth.NewVerifier(background, cfg.Auth)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		server.echo.Use(server.authenticate(verifier))
 	}
+
 	server.registerRoutes()
-	return server
+	return server, nil
 }
 
 func (s *EchoServer) Start() error {
-	// Retrieve environment variables
-	port := ":" + os.Getenv("DEFAULTPORT")
+	cfg := s.config.Server
+	for _, srv := range []*http.Server{s.echo.Server, s.echo.TLSServer} {
+		srv.ReadTimeout = cfg.ReadTimeout
+		srv.WriteTimeout = cfg.WriteTimeout
+		srv.IdleTimeout = cfg.IdleTimeout
+	}
 
-	if err := s.echo.Start(port); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server shutdown occurred: %s", err)
-		return err
+	port := ":" + strconv.Itoa(cfg.Port)
+
+	var err error
+	if cfg.TLS.Enabled() {
+		err = s.echo.StartTLS(port, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	} else {
+		err = s.echo.Start(port)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server stopped: %w", err)
 	}
 	return nil
 }
 
+// Shutdown fails readiness, keeps serving for the configured delay so load
+// balancers can take the instance out of rotation, then stops accepting
+// connections and waits for in-flight requests until ctx is done. Start
+// returns once it has been called.
+func (s *EchoServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	defer s.stop()
+
+	select {
+	case <-time.After(s.config.Server.ShutdownDelay):
+	case <-ctx.Done():
+	}
+
+	return s.echo.Shutdown(ctx)
+}
+
 func (s *EchoServer) registerRoutes() {
 	s.echo.GET("/readiness", s.Readiness)
 	s.echo.GET("/liveness", s.Liveness)
 
 	// Serve the Swagger documentation
-	s.echo.GET("/swagger/*", echoSwagger.WrapHandler)
+	if s.config.Features.Swagger {
+		s.echo.GET("/swagger/*", echoSwagger.WrapHandler)
+	}
 
 	cg := s.echo.Group("/customers")
-	cg.GET("", s.GetAllCustomers)
-	cg.GET("/:id", s.GetCustomerById)
-	cg.POST("", s.AddCustomer)
-	cg.PUT("", s.UpdateCustomer)
-	cg.DELETE("", s.DeleteCustomer)
+	cg.GET("", s.GetAllCustomers, s.require("customers:read"))
+	cg.GET("/:id", s.GetCustomerById, s.require("customers:read"))
+	cg.POST("", s.AddCustomer, s.require("customers:write"))
+	cg.POST("\\:batch", s.BatchCustomers, s.require("customers:write", "customers:delete"))
+	cg.GET("/export", s.ExportCustomers, s.require("customers:read"))
+	cg.POST("/import", s.ImportCustomers, s.require("customers:write"))
+	cg.PUT("/:id", s.UpdateCustomer, s.require("customers:write"))
+	cg.PATCH("/:id", s.PatchCustomer, s.require("customers:write"))
+	cg.DELETE("/:id", s.DeleteCustomer, s.require("customers:delete"))
+	if s.config.Features.LegacyRoutes {
+		cg.PUT("", s.UpdateCustomer, deprecated, s.require("customers:write"))
+		cg.DELETE("", s.DeleteCustomer, deprecated, s.require("customers:delete"))
+	}
+	cg.POST("/:id/restore", s.RestoreCustomer, s.require("customers:write"))
+	cg.DELETE("/:id/purge", s.PurgeCustomer, s.require("customers:purge"))
+	cg.GET("/:id/pets", s.GetAllPets, s.require("customers:read"))
+	cg.POST("/:id/pets", s.AddPet, s.require("customers:write"))
+	cg.GET("/:id/pets/:pet_id", s.GetPetById, s.require("customers:read"))
+	cg.PUT("/:id/pets/:pet_id", s.UpdatePet, s.require("customers:write"))
+	cg.PATCH("/:id/pets/:pet_id", s.PatchPet, s.require("customers:write"))
+	cg.DELETE("/:id/pets/:pet_id", s.DeletePet, s.require("customers:delete"))
 
 	pg := s.echo.Group("/products")
-	pg.GET("", s.GetAllProducts)
-	pg.GET("/search/:searchterm", s.SearchProducts)
-	pg.GET("/vendor/:id", s.GetAllProductsByVendor)
-	pg.GET("/:id", s.GetProductById)
-	pg.POST("", s.AddProduct)
-	pg.PUT("", s.UpdateProduct)
-	pg.DELETE("", s.DeleteProduct)
+	pg.GET("", s.GetAllProducts, s.require("products:read"))
+	pg.GET("/search", s.SearchProducts, s.require("products:read"))
+	pg.GET("/search/:searchterm", s.SearchProductsByTerm, s.require("products:read"))
+	pg.GET("/vendor/:id", s.GetAllProductsByVendor, s.require("products:read"))
+	pg.GET("/vendor/:id/low-stock", s.GetLowStockProductsByVendor, s.require("products:read"))
+	pg.GET("/:id", s.GetProductById, s.require("products:read"))
+	pg.GET("/:id/stock", s.GetProductStock, s.require("products:read"))
+	pg.PUT("/:id/stock", s.SetReorderThreshold, s.require("products:write"))
+	pg.GET("/:id/stock/movements", s.GetStockMovements, s.require("products:read"))
+	pg.POST("/:id/stock/movements", s.RecordStockMovement, s.require("products:write"))
+	pg.POST("", s.AddProduct, s.require("products:write"))
+	pg.POST("\\:batch", s.BatchProducts, s.require("products:write", "products:delete"))
+	pg.GET("/export", s.ExportProducts, s.require("products:read"))
+	pg.POST("/import", s.ImportProducts, s.require("products:write"))
+	pg.PUT("/:id", s.UpdateProduct, s.require("products:write"))
+	pg.PATCH("/:id", s.PatchProduct, s.require("products:write"))
+	pg.DELETE("/:id", s.DeleteProduct, s.require("products:delete"))
+	if s.config.Features.LegacyRoutes {
+		pg.PUT("", s.UpdateProduct, deprecated, s.require("products:write"))
+		pg.DELETE("", s.DeleteProduct, deprecated, s.require("products:delete"))
+	}
+	pg.POST("/:id/restore", s.RestoreProduct, s.require("products:write"))
+	pg.DELETE("/:id/purge", s.PurgeProduct, s.require("products:purge"))
 
 	sg := s.echo.Group("/services")
-	sg.GET("", s.GetAllServices)
-	sg.GET("/:id", s.GetServiceById)
-	sg.POST("", s.AddService)
-	sg.PUT("", s.UpdateService)
-	sg.DELETE("", s.DeleteService)
+	sg.GET("", s.GetAllServices, s.require("services:read"))
+	sg.GET("/search", s.SearchServices, s.require("services:read"))
+	sg.GET("/:id", s.GetServiceById, s.require("services:read"))
+	sg.POST("", s.AddService, s.require("services:write"))
+	sg.POST("\\:batch", s.BatchServices, s.require("services:write", "services:delete"))
+	sg.GET("/export", s.ExportServices, s.require("services:read"))
+	sg.POST("/import", s.ImportServices, s.require("services:write"))
+	sg.PUT("/:id", s.UpdateService, s.require("services:write"))
+	sg.PATCH("/:id", s.PatchService, s.require("services:write"))
+	sg.DELETE("/:id", s.DeleteService, s.require("services:delete"))
+	if s.config.Features.LegacyRoutes {
+		sg.PUT("", s.UpdateService, deprecated, s.require("services:write"))
+		sg.DELETE("", s.DeleteService, deprecated, s.require("services:delete"))
+	}
+	sg.POST("/:id/restore", s.RestoreService, s.require("services:write"))
+	sg.DELETE("/:id/purge", s.PurgeService, s.require("services:purge"))
 
 	vg := s.echo.Group("/vendors")
-	vg.GET("", s.GetAllVendors)
-	vg.GET("/:id", s.GetVendorById)
-	vg.POST("", s.AddVendor)
-	vg.PUT("", s.UpdateVendor)
-	vg.DELETE("", s.DeleteVendor)
+	vg.GET("", s.GetAllVendors, s.require("vendors:read"))
+	vg.GET("/search", s.SearchVendors, s.require("vendors:read"))
+	vg.GET("/:id", s.GetVendorById, s.require("vendors:read"))
+	vg.POST("", s.AddVendor, s.require("vendors:write"))
+	vg.POST("\\:batch", s.BatchVendors, s.require("vendors:write", "vendors:delete"))
+	vg.GET("/export", s.ExportVendors, s.require("vendors:read"))
+	vg.POST("/import", s.ImportVendors, s.require("vendors:write"))
+	vg.PUT("/:id", s.UpdateVendor, s.require("vendors:write"))
+	vg.PATCH("/:id", s.PatchVendor, s.require("vendors:write"))
+	vg.DELETE("/:id", s.DeleteVendor, s.require("vendors:delete"))
+	if s.config.Features.LegacyRoutes {
+		vg.PUT("", s.UpdateVendor, deprecated, s.require("vendors:write"))
+		vg.DELETE("", s.DeleteVendor, deprecated, s.require("vendors:delete"))
+	}
+	vg.POST("/:id/restore", s.RestoreVendor, s.require("vendors:write"))
+	vg.DELETE("/:id/purge", s.PurgeVendor, s.require("vendors:purge"))
+	vg.GET("/:id/purchase-orders", s.GetAllPurchaseOrders, s.require("purchase-orders:read"))
+	vg.POST("/:id/purchase-orders", s.AddPurchaseOrder, s.require("purchase-orders:write"))
+	vg.GET("/:id/purchase-orders/:po_id", s.GetPurchaseOrderById, s.require("purchase-orders:read"))
+	vg.PUT("/:id/purchase-orders/:po_id/status", s.UpdatePurchaseOrderStatus, s.require("purchase-orders:write"))
+	vg.POST("/:id/purchase-orders/:po_id/receipts", s.ReceivePurchaseOrder, s.require("purchase-orders:write"))
+
+	og := s.echo.Group("/orders")
+	og.GET("", s.GetAllOrders, s.require("orders:read"))
+	og.GET("/:id", s.GetOrderById, s.require("orders:read"))
+	og.POST("", s.AddOrder, s.require("orders:write"))
+	og.DELETE("/:id", s.DeleteOrder, s.require("orders:delete"))
+	if s.config.Features.LegacyRoutes {
+		og.DELETE("", s.DeleteOrder, deprecated, s.require("orders:delete"))
+	}
+
+	ag := s.echo.Group("/appointments")
+	ag.GET("", s.GetAllAppointments, s.require("appointments:read"))
+	ag.GET("/availability", s.GetAvailability, s.require("appointments:read"))
+	ag.GET("/:id", s.GetAppointmentById, s.require("appointments:read"))
+	ag.POST("", s.AddAppointment, s.require("appointments:write"))
+	ag.DELETE("/:id", s.DeleteAppointment, s.require("appointments:delete"))
+	if s.config.Features.LegacyRoutes {
+		ag.DELETE("", s.DeleteAppointment, deprecated, s.require("appointments:delete"))
+	}
+
+	rg := s.echo.Group("/roles")
+	rg.GET("", s.GetAllRoles, s.require("roles:read"))
+	rg.GET("/:id", s.GetRoleById, s.require("roles:read"))
+	rg.POST("", s.AddRole, s.require("roles:write"))
+	rg.PUT("/:id", s.UpdateRole, s.require("roles:write"))
+	rg.DELETE("/:id", s.DeleteRole, s.require("roles:delete"))
+	rg.GET("/:id/assignments", s.GetRoleAssignments, s.require("roles:read"))
+	rg.POST("/:id/assignments", s.AddRoleAssignment, s.require("roles:write"))
+	rg.DELETE("/:id/assignments/:subject", s.DeleteRoleAssignment, s.require("roles:write"))
+
+	kg := s.echo.Group("/admin/api-keys")
+	kg.GET("", s.GetAllAPIKeys, s.require("api-keys:read"))
+	kg.GET("/:id", s.GetAPIKeyById, s.require("api-keys:read"))
+	kg.POST("", s.AddAPIKey, s.require("api-keys:write"))
+	kg.DELETE("/:id", s.RevokeAPIKey, s.require("api-keys:delete"))
+
+	s.echo.GET("/audit", s.GetAuditEntries, s.require("audit:read"))
 }
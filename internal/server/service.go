@@ -2,11 +2,13 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/johnifegwu/go-microservices/internal/transfer"
 	"github.com/labstack/echo/v4"
 )
 
@@ -18,16 +20,51 @@ import (
 // @Produce  json
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Service
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Param include_deleted query bool false "Include soft-deleted records"
+// @Success 200 {object} models.Page[models.Service]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /services [get]
 func (s *EchoServer) GetAllServices(ctx echo.Context) error {
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
-	services, err := s.DB.GetAllServices(ctx.Request().Context(), pageindex, pagesize)
+	includeDeleted, _ := strconv.ParseBool(ctx.QueryParam("include_deleted"))
+	services, err := s.DB.GetAllServices(ctx.Request().Context(), pageRequest(ctx), includeDeleted)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, services)
+	return writePage(ctx, services)
+}
+
+// SearchServices godoc
+// @Summary Search services
+// @Description Full-text search over service names with a fuzzy fallback for typos. Results are ranked, highlighted and can be filtered, e.g. by a price range
+// @Tags services
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search text"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price<=50; repeat to combine" collectionFormat(multi)
+// @Success 200 {object} models.Page[models.SearchHit[models.Service]]
+// @Failure 400 {object} server.ErrorResponse "Missing search text or invalid filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/search [get]
+func (s *EchoServer) SearchServices(ctx echo.Context) error {
+	hits, err := s.DB.SearchServices(ctx.Request().Context(), ctx.QueryParam("q"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, hits)
 }
 
 // GetServiceById godoc
@@ -37,15 +74,22 @@ func (s *EchoServer) GetAllServices(ctx echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Service ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
 // @Success 200 {object} models.Service
+// @Header 200 {string} ETag "Version of the service"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /services/{id} [get]
 func (s *EchoServer) GetServiceById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	service, err := s.DB.GetServiceById(ctx.Request().Context(), id)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, service)
+	return writeVersioned(ctx, http.StatusOK, service.Version, service)
 }
 
 // AddService godoc
@@ -56,85 +100,160 @@ func (s *EchoServer) GetServiceById(ctx echo.Context) error {
 // @Produce  json
 // @Param service body models.Service true "Service to add"
 // @Success 201 {object} models.Service
-// @Failure 409 {object} dberrors.ConflictError
-// @Failure 415 {object} string "Unsupported Media Type"
+// @Header 201 {string} ETag "Version of the service"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /services [post]
 func (s *EchoServer) AddService(ctx echo.Context) error {
 	service := new(models.Service)
 
 	if err := ctx.Bind(service); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
 	service, err := s.DB.AddService(ctx.Request().Context(), service)
 
 	if err != nil {
-		switch err.(type) {
-		case *dberrors.ConflictError:
-			return ctx.JSON(http.StatusConflict, err)
-		default:
-			return ctx.JSON(http.StatusInternalServerError, err)
-		}
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, service)
+	return writeVersioned(ctx, http.StatusCreated, service.Version, service)
 }
 
 // UpdateService godoc
 // @Summary Update an existing service
-// @Description Update a service's details by providing its ID
+// @Description Replace a service's details. The ID on the path wins; the body may repeat it or leave it out. The former PUT /services that took the ID from the body still works but is deprecated
 // @Tags services
 // @Accept  json
 // @Produce  json
-// @Param service_id path string true "Service ID"
+// @Param id path string true "Service ID"
 // @Param service body models.Service true "Updated service data"
+// @Param If-Match header string true "ETag of the service as last read, or * for any version"
 // @Success 201 {object} models.Service
-// @Failure 400 {object} string "Bad Request"
-// @Failure 415 {object} string "Unsupported Media Type"
-// @Router /services/{service_id} [put]
+// @Header 201 {string} ETag "Version of the service"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The service has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [put]
 func (s *EchoServer) UpdateService(ctx echo.Context) error {
 	service := new(models.Service)
 
 	if err := ctx.Bind(service); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
-	ID, errUUID := uuid.Parse(ctx.Param("service_id"))
-	if errUUID == nil {
-		if ID != service.ServiceID {
-			return ctx.JSON(http.StatusBadRequest, "id on path doesn't match id on body")
+	// The legacy route has no ID on the path and takes the one in the body
+	if id := ctx.Param("id"); id != "" {
+		ID, err := dberrors.ParseID(id)
+		if err != nil {
+			return err
+		}
+		if service.ServiceID != uuid.Nil && service.ServiceID != ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
 		}
+		service.ServiceID = ID
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
 	}
+	service.Version = version
 
-	service, err := s.DB.UpdateService(ctx.Request().Context(), service)
+	service, err = s.DB.UpdateService(ctx.Request().Context(), service)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, service)
+	return writeVersioned(ctx, http.StatusCreated, service.Version, service)
+}
+
+// PatchService godoc
+// @Summary Patch a service
+// @Description Change only some fields of a service with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Fields the patch leaves alone keep their stored values
+// @Tags services
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Service ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string true "ETag of the service as last read, or * for any version"
+// @Success 200 {object} models.Service
+// @Header 200 {string} ETag "Version of the service"
+// @Failure 400 {object} server.ErrorResponse "Malformed patch"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The service has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "The patch cannot be applied or the result is invalid"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [patch]
+func (s *EchoServer) PatchService(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	apply, err := readPatch[models.Service](ctx)
+	if err != nil {
+		return err
+	}
+
+	service, err := s.DB.PatchService(ctx.Request().Context(), ctx.Param("id"), version, apply)
+	if err != nil {
+		return err
+	}
+
+	return writeVersioned(ctx, http.StatusOK, service.Version, service)
 }
 
 // DeleteService godoc
 // @Summary Delete a service
-// @Description Delete a service from the database by its ID
+// @Description Soft-delete a service by its ID. The record is hidden but can be restored. The former DELETE /services?id= still works but is deprecated
 // @Tags services
 // @Accept  json
 // @Produce  json
-// @Param id query string true "Service ID"
+// @Param id path string true "Service ID"
+// @Param If-Match header string true "ETag of the service as last read, or * for any version"
 // @Success 200 {object} server.Response
-// @Failure 500 {object} dberrors.ZeroRowsAffectedError
-// @Router /services [delete]
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The service has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [delete]
 func (s *EchoServer) DeleteService(ctx echo.Context) error {
-	var serviceId = ctx.QueryParam("id")
+	var serviceId = resourceID(ctx)
 
-	rowsaffected, err := s.DB.DeleteService(ctx.Request().Context(), serviceId)
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteService(ctx.Request().Context(), serviceId, version)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
 	if rowsaffected < 1 {
-		dberr := new(dberrors.ZeroRowsAffectedError)
-		return ctx.JSON(http.StatusInternalServerError, dberr.Error())
+		return &dberrors.ZeroRowsAffectedError{}
 	}
 
 	response := server.Response{
@@ -143,3 +262,135 @@ func (s *EchoServer) DeleteService(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// RestoreService godoc
+// @Summary Restore a deleted service
+// @Description Bring back a soft-deleted service
+// @Tags services
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Service ID"
+// @Success 200 {object} models.Service
+// @Header 200 {string} ETag "Version of the service"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id}/restore [post]
+func (s *EchoServer) RestoreService(ctx echo.Context) error {
+	service, err := s.DB.RestoreService(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, service.Version, service)
+}
+
+// PurgeService godoc
+// @Summary Permanently delete a service
+// @Description Remove a soft-deleted service for good. Refused while orders or appointments still reference it. Needs services:purge, which only the admin role holds by default
+// @Tags services
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Service ID"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id}/purge [delete]
+func (s *EchoServer) PurgeService(ctx echo.Context) error {
+	rowsaffected, err := s.DB.PurgeService(ctx.Request().Context(), ctx.Param("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record purged successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// BatchServices godoc
+// @Summary Create, update and delete services in one batch
+// @Description Apply many writes in a single transaction. In atomic mode (the default) any failure rolls back the whole batch; in partial mode successful writes are kept. Each write's outcome is reported with its own status
+// @Tags services
+// @Accept  json
+// @Produce  json
+// @Param batch body models.Batch[models.Service] true "Writes to apply"
+// @Success 200 {object} server.BatchResponse "Every write succeeded"
+// @Success 207 {object} server.BatchResponse "At least one write failed"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services:batch [post]
+func (s *EchoServer) BatchServices(ctx echo.Context) error {
+	batch := new(models.Batch[models.Service])
+
+	if err := ctx.Bind(batch); err != nil {
+		return err
+	}
+
+	results, err := s.DB.BatchServices(ctx.Request().Context(), batch)
+	if err != nil {
+		return err
+	}
+	return writeBatch(ctx, batch.Mode, results)
+}
+
+// ExportServices godoc
+// @Summary Export services
+// @Description Stream every live service as a CSV or JSON lines download. CSV files start with a header row of column names, and cells a spreadsheet would run as a formula are prefixed with ' (importing the file removes it again); JSON lines hold one service per line as the API returns it. Filters narrow the export as they do the listing
+// @Tags services
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "File format" Enums(csv, jsonl) default(csv)
+// @Param filter query []string false "Filters of the form field<operator>value, e.g. name~acme" collectionFormat(multi)
+// @Success 200 {file} file
+// @Failure 400 {object} server.ErrorResponse "Invalid format or filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/export [get]
+func (s *EchoServer) ExportServices(ctx echo.Context) error {
+	return writeExport(ctx, "services", transfer.Services, s.DB.ExportServices)
+}
+
+// ImportServices godoc
+// @Summary Import services
+// @Description Create and update services from a CSV or JSON lines file, as exported. Rows without service_id create a service, rows with one update the columns they give, leaving the rest unchanged. Headers that differ from the column names can be mapped with map=header=column. In atomic mode (the default) any failing row rolls back the whole import; in partial mode the good rows are kept. A dry run applies every row and then rolls back. Each row is reported by its line in the file.
+// @Tags services
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Produce  json
+// @Param file body string true "Rows to import"
+// @Param format query string false "File format, taken from the Content-Type when omitted" Enums(csv, jsonl)
+// @Param map query []string false "Header mappings of the form header=column" collectionFormat(multi)
+// @Param mode query string false "Import mode" Enums(atomic, partial) default(atomic)
+// @Param dry_run query bool false "Validate and report without keeping any change"
+// @Success 200 {object} server.ImportResponse "Every row succeeded"
+// @Success 207 {object} server.ImportResponse "At least one row failed"
+// @Failure 400 {object} server.ErrorResponse "Invalid format, map or dry_run"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unreadable header, empty file or too many rows"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/import [post]
+func (s *EchoServer) ImportServices(ctx echo.Context) error {
+	return readImport(ctx, transfer.Services, s.DB.ImportServices)
+}
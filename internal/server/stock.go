@@ -0,0 +1,171 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetProductStock godoc
+// @Summary Get product stock
+// @Description Get the on-hand quantity and reorder threshold of a product
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
+// @Success 200 {object} models.StockLevel
+// @Header 200 {string} ETag "Version of the product"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/stock [get]
+func (s *EchoServer) GetProductStock(ctx echo.Context) error {
+	level, err := s.DB.GetProductStock(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, level.Version, level)
+}
+
+// SetReorderThreshold godoc
+// @Summary Set reorder threshold
+// @Description Set the quantity at or below which a product is reported as low on stock
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param stock body models.StockLevel true "Stock level carrying the new reorder_threshold"
+// @Param If-Match header string true "ETag of the product as last read, or * for any version"
+// @Success 200 {object} models.StockLevel
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The product has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/stock [put]
+func (s *EchoServer) SetReorderThreshold(ctx echo.Context) error {
+	level := new(models.StockLevel)
+
+	if err := ctx.Bind(level); err != nil {
+		return err
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	level, err = s.DB.SetReorderThreshold(ctx.Request().Context(), ctx.Param("id"), level.ReorderThreshold, version)
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, level.Version, level)
+}
+
+// GetStockMovements godoc
+// @Summary Get stock movements
+// @Description Get the stock ledger of a product, newest first, with optional pagination
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.StockMovement]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/stock/movements [get]
+func (s *EchoServer) GetStockMovements(ctx echo.Context) error {
+
+	movements, err := s.DB.GetStockMovements(ctx.Request().Context(), ctx.Param("id"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, movements)
+}
+
+// RecordStockMovement godoc
+// @Summary Record a stock movement
+// @Description Record a receipt, sale or adjustment against a product and update its on-hand quantity
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param movement body models.StockMovement true "Movement to record"
+// @Success 201 {object} models.StockLevel
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/{id}/stock/movements [post]
+func (s *EchoServer) RecordStockMovement(ctx echo.Context) error {
+	movement := new(models.StockMovement)
+
+	if err := ctx.Bind(movement); err != nil {
+		return err
+	}
+
+	productID, errUUID := dberrors.ParseID(ctx.Param("id"))
+	if errUUID != nil {
+		return errUUID
+	}
+	movement.ProductID = productID
+
+	level, err := s.DB.RecordStockMovement(ctx.Request().Context(), movement)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, level)
+}
+
+// GetLowStockProductsByVendor godoc
+// @Summary Get low-stock products by vendor
+// @Description Get a vendor's products whose on-hand quantity is at or below their reorder threshold
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Success 200 {object} models.Page[models.Product]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /products/vendor/{id}/low-stock [get]
+func (s *EchoServer) GetLowStockProductsByVendor(ctx echo.Context) error {
+	vendorid := ctx.Param("id")
+
+	products, err := s.DB.GetLowStockProductsByVendor(ctx.Request().Context(), vendorid, pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, products)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/johnifegwu/go-microservices/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// exportFlushRows is how many records are written between flushes, so
+// clients see an export arrive as it is read.
+const exportFlushRows = 100
+
+// exporter streams the records matching filters to each.
+type exporter[T any] func(ctx context.Context, filters []string, each func(item *T) error) error
+
+// importer applies the rows read from an import file.
+type importer[T any] func(ctx context.Context, rows []models.ImportRow[T], options models.ImportOptions) ([]models.BatchResult[T], error)
+
+// writeExport streams the records of export as a file download named after
+// entity, in the format asked for by ?format= (CSV by default). An error once
+// the download has started can only cut it short, so it is logged. Exports
+// are exempt from the server's write timeout, which would otherwise end a
+// large one partway through the file.
+func writeExport[T any](ctx echo.Context, entity string, codec transfer.Codec[T], export exporter[T]) error {
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = transfer.CSV
+	}
+
+	response := ctx.Response()
+	writer, err := transfer.NewWriter(format, response, codec)
+	if err != nil {
+		return err
+	}
+
+	// Recorders used in place of a connection have no deadline to clear
+	if err := http.NewResponseController(response).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	response.Header().Set(echo.HeaderContentType, transfer.ContentType(format))
+	response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+entity+"."+format+`"`)
+
+	rows := 0
+	err = export(ctx.Request().Context(), ctx.QueryParams()["filter"], func(item *T) error {
+		if err := writer.Write(item); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			response.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err != nil {
+		if !response.Committed {
+			response.Header().Del(echo.HeaderContentDisposition)
+			return err
+		}
+		ctx.Logger().Error(err)
+	}
+	return nil
+}
+
+// readImport reads and applies an import file sent as the request body. The
+// format comes from ?format= or else the Content-Type, headers are renamed by
+// any ?map=header=column pairs, ?mode= is atomic or partial and ?dry_run=true
+// rolls everything back once the report is made.
+func readImport[T any](ctx echo.Context, codec transfer.Codec[T], apply importer[T]) error {
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = transfer.FormatOf(ctx.Request().Header.Get(echo.HeaderContentType))
+	}
+	if format == "" {
+		return echo.ErrUnsupportedMediaType
+	}
+
+	mapping, err := transfer.ParseMapping(ctx.QueryParams()["map"])
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := strconv.ParseBool(ctx.QueryParam("dry_run"))
+	if err != nil && ctx.QueryParam("dry_run") != "" {
+		return &dberrors.InvalidQueryError{Parameter: "dry_run", Message: "must be true or false"}
+	}
+	options := models.ImportOptions{Mode: ctx.QueryParam("mode"), DryRun: dryRun}
+
+	reader, err := transfer.NewReader(format, ctx.Request().Body, mapping)
+	if err != nil {
+		return err
+	}
+
+	var rows []models.ImportRow[T]
+	for len(rows) <= models.MaxImportRows {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows = append(rows, codec.Decode(record))
+	}
+
+	results, err := apply(ctx.Request().Context(), rows, options)
+	if err != nil {
+		return err
+	}
+
+	response := server.ImportResponse{Mode: options.Mode, DryRun: options.DryRun}
+	if response.Mode == "" {
+		response.Mode = models.BatchAtomic
+	}
+	response.Results, response.Succeeded, response.Failed = batchItems(ctx, results)
+	return ctx.JSON(batchStatus(response.Failed), response)
+}
@@ -2,11 +2,13 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/johnifegwu/go-microservices/internal/dberrors"
 	"github.com/johnifegwu/go-microservices/internal/models"
 	server "github.com/johnifegwu/go-microservices/internal/server/models"
+	"github.com/johnifegwu/go-microservices/internal/transfer"
 	"github.com/labstack/echo/v4"
 )
 
@@ -18,16 +20,51 @@ import (
 // @Produce  json
 // @Param pageindex query string false "Page index for pagination"
 // @Param pagesize query string false "Page size for pagination"
-// @Success 200 {array} models.Vendor
+// @Param cursor query string false "Cursor from a previous page; takes precedence over pageindex"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as price>=10 or name~cat; repeat to combine" collectionFormat(multi)
+// @Param sort query string false "Comma separated fields to sort by, prefixed with - for descending, e.g. -price,name"
+// @Param include_deleted query bool false "Include soft-deleted records"
+// @Success 200 {object} models.Page[models.Vendor]
+// @Failure 400 {object} server.ErrorResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /vendors [get]
 func (s *EchoServer) GetAllVendors(ctx echo.Context) error {
-	pageindex := ctx.QueryParam("pageindex")
-	pagesize := ctx.QueryParam("pagesize")
-	vendors, err := s.DB.GetAllVendors(ctx.Request().Context(), pageindex, pagesize)
+	includeDeleted, _ := strconv.ParseBool(ctx.QueryParam("include_deleted"))
+	vendors, err := s.DB.GetAllVendors(ctx.Request().Context(), pageRequest(ctx), includeDeleted)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, vendors)
+	return writePage(ctx, vendors)
+}
+
+// SearchVendors godoc
+// @Summary Search vendors
+// @Description Full-text search over vendor names and contacts with a fuzzy fallback for typos. Results are ranked and highlighted
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search text"
+// @Param pageindex query string false "Page index for pagination"
+// @Param pagesize query string false "Page size for pagination"
+// @Param include_total query bool false "Include the total number of matching records"
+// @Param filter query []string false "Filters such as email~@example.com; repeat to combine" collectionFormat(multi)
+// @Success 200 {object} models.Page[models.SearchHit[models.Vendor]]
+// @Failure 400 {object} server.ErrorResponse "Missing search text or invalid filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/search [get]
+func (s *EchoServer) SearchVendors(ctx echo.Context) error {
+	hits, err := s.DB.SearchVendors(ctx.Request().Context(), ctx.QueryParam("q"), pageRequest(ctx))
+	if err != nil {
+		return err
+	}
+	return writePage(ctx, hits)
 }
 
 // GetVendorById godoc
@@ -37,15 +74,22 @@ func (s *EchoServer) GetAllVendors(ctx echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Vendor ID"
+// @Param If-None-Match header string false "ETag from an earlier read; a match is answered with 304"
 // @Success 200 {object} models.Vendor
+// @Header 200 {string} ETag "Version of the vendor"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /vendors/{id} [get]
 func (s *EchoServer) GetVendorById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	vendor, err := s.DB.GetVendorById(ctx.Request().Context(), id)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusOK, vendor)
+	return writeVersioned(ctx, http.StatusOK, vendor.Version, vendor)
 }
 
 // AddVendor godoc
@@ -56,85 +100,162 @@ func (s *EchoServer) GetVendorById(ctx echo.Context) error {
 // @Produce  json
 // @Param vendor body models.Vendor true "Vendor to add"
 // @Success 201 {object} models.Vendor
-// @Failure 409 {object} dberrors.ConflictError
-// @Failure 415 {object} string "Unsupported Media Type"
+// @Header 201 {string} ETag "Version of the vendor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /vendors [post]
 func (s *EchoServer) AddVendor(ctx echo.Context) error {
 	vendor := new(models.Vendor)
 
 	if err := ctx.Bind(vendor); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
 	vendor, err := s.DB.AddVendor(ctx.Request().Context(), vendor)
 
 	if err != nil {
-		switch err.(type) {
-		case *dberrors.ConflictError:
-			return ctx.JSON(http.StatusConflict, err)
-		default:
-			return ctx.JSON(http.StatusInternalServerError, err)
-		}
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, vendor)
+	return writeVersioned(ctx, http.StatusCreated, vendor.Version, vendor)
 }
 
 // UpdateVendor godoc
 // @Summary Update an existing vendor
-// @Description Update a vendor's details by providing its ID
+// @Description Replace a vendor's details. The ID on the path wins; the body may repeat it or leave it out. The former PUT /vendors that took the ID from the body still works but is deprecated
 // @Tags vendors
 // @Accept  json
 // @Produce  json
-// @Param vendor_id path string true "Vendor ID"
+// @Param id path string true "Vendor ID"
 // @Param vendor body models.Vendor true "Updated vendor data"
+// @Param If-Match header string true "ETag of the vendor as last read, or * for any version"
 // @Success 201 {object} models.Vendor
-// @Failure 400 {object} string "Bad Request"
-// @Failure 415 {object} string "Unsupported Media Type"
-// @Router /vendors/{vendor_id} [put]
+// @Header 201 {string} ETag "Version of the vendor"
+// @Failure 400 {object} server.ErrorResponse "Bad Request"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The vendor has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id} [put]
 func (s *EchoServer) UpdateVendor(ctx echo.Context) error {
 	vendor := new(models.Vendor)
 
 	if err := ctx.Bind(vendor); err != nil {
-		return ctx.JSON(http.StatusUnsupportedMediaType, err)
+		return err
 	}
 
-	ID, errUUID := uuid.Parse(ctx.Param("vendor_id"))
-	if errUUID == nil {
-		if ID != vendor.VendorID {
-			return ctx.JSON(http.StatusBadRequest, "ID on path doesn't match ID in body")
+	// The legacy route has no ID on the path and takes the one in the body
+	if id := ctx.Param("id"); id != "" {
+		ID, err := dberrors.ParseID(id)
+		if err != nil {
+			return err
+		}
+		if vendor.VendorID != uuid.Nil && vendor.VendorID != ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "id on path doesn't match id on body")
 		}
+		vendor.VendorID = ID
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
 	}
+	vendor.Version = version
 
-	vendor, err := s.DB.UpdateVendor(ctx.Request().Context(), vendor)
+	vendor, err = s.DB.UpdateVendor(ctx.Request().Context(), vendor)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return ctx.JSON(http.StatusCreated, vendor)
+	return writeVersioned(ctx, http.StatusCreated, vendor.Version, vendor)
+}
+
+// PatchVendor godoc
+// @Summary Patch a vendor
+// @Description Change only some fields of a vendor with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Fields the patch leaves alone keep their stored values
+// @Tags vendors
+// @Accept  application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string true "ETag of the vendor as last read, or * for any version"
+// @Success 200 {object} models.Vendor
+// @Header 200 {string} ETag "Version of the vendor"
+// @Failure 400 {object} server.ErrorResponse "Malformed patch"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The vendor has changed since it was read"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "The patch cannot be applied or the result is invalid"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id} [patch]
+func (s *EchoServer) PatchVendor(ctx echo.Context) error {
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	apply, err := readPatch[models.Vendor](ctx)
+	if err != nil {
+		return err
+	}
+
+	vendor, err := s.DB.PatchVendor(ctx.Request().Context(), ctx.Param("id"), version, apply)
+	if err != nil {
+		return err
+	}
+
+	return writeVersioned(ctx, http.StatusOK, vendor.Version, vendor)
 }
 
 // DeleteVendor godoc
 // @Summary Delete a vendor
-// @Description Delete a vendor from the database by its ID
+// @Description Soft-delete a vendor by its ID. Vendors with live products are refused unless cascade is set. The former DELETE /vendors?id= still works but is deprecated
 // @Tags vendors
 // @Accept  json
 // @Produce  json
-// @Param id query string true "Vendor ID"
+// @Param id path string true "Vendor ID"
+// @Param cascade query bool false "Also soft-delete the vendor's products"
+// @Param If-Match header string true "ETag of the vendor as last read, or * for any version"
 // @Success 200 {object} server.Response
-// @Failure 500 {object} dberrors.ZeroRowsAffectedError
-// @Router /vendors [delete]
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 412 {object} server.ErrorResponse "The vendor has changed since it was read"
+// @Failure 428 {object} server.ErrorResponse "If-Match is missing"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id} [delete]
 func (s *EchoServer) DeleteVendor(ctx echo.Context) error {
-	var vendorId = ctx.QueryParam("id")
+	var vendorId = resourceID(ctx)
+	cascade, _ := strconv.ParseBool(ctx.QueryParam("cascade"))
 
-	rowsaffected, err := s.DB.DeleteVendor(ctx.Request().Context(), vendorId)
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsaffected, err := s.DB.DeleteVendor(ctx.Request().Context(), vendorId, cascade, version)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 
 	if rowsaffected < 1 {
-		dberr := new(dberrors.ZeroRowsAffectedError)
-		return ctx.JSON(http.StatusInternalServerError, dberr.Error())
+		return &dberrors.ZeroRowsAffectedError{}
 	}
 
 	response := server.Response{
@@ -143,3 +264,135 @@ func (s *EchoServer) DeleteVendor(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusOK, response)
 }
+
+// RestoreVendor godoc
+// @Summary Restore a deleted vendor
+// @Description Bring back a soft-deleted vendor
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Success 200 {object} models.Vendor
+// @Header 200 {string} ETag "Version of the vendor"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/restore [post]
+func (s *EchoServer) RestoreVendor(ctx echo.Context) error {
+	vendor, err := s.DB.RestoreVendor(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return writeVersioned(ctx, http.StatusOK, vendor.Version, vendor)
+}
+
+// PurgeVendor godoc
+// @Summary Permanently delete a vendor
+// @Description Remove a soft-deleted vendor for good. Refused while products or purchase orders still reference it. Needs vendors:purge, which only the admin role holds by default
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Success 200 {object} server.Response
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 404 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/{id}/purge [delete]
+func (s *EchoServer) PurgeVendor(ctx echo.Context) error {
+	rowsaffected, err := s.DB.PurgeVendor(ctx.Request().Context(), ctx.Param("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if rowsaffected < 1 {
+		return &dberrors.ZeroRowsAffectedError{}
+	}
+
+	response := server.Response{
+		Status:  "Ok",
+		Message: "Record purged successfully",
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// BatchVendors godoc
+// @Summary Create, update and delete vendors in one batch
+// @Description Apply many writes in a single transaction. In atomic mode (the default) any failure rolls back the whole batch; in partial mode successful writes are kept. Each write's outcome is reported with its own status. Vendors are deleted without cascading, so one that still has products fails
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param batch body models.Batch[models.Vendor] true "Writes to apply"
+// @Success 200 {object} server.BatchResponse "Every write succeeded"
+// @Success 207 {object} server.BatchResponse "At least one write failed"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Validation failed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors:batch [post]
+func (s *EchoServer) BatchVendors(ctx echo.Context) error {
+	batch := new(models.Batch[models.Vendor])
+
+	if err := ctx.Bind(batch); err != nil {
+		return err
+	}
+
+	results, err := s.DB.BatchVendors(ctx.Request().Context(), batch)
+	if err != nil {
+		return err
+	}
+	return writeBatch(ctx, batch.Mode, results)
+}
+
+// ExportVendors godoc
+// @Summary Export vendors
+// @Description Stream every live vendor as a CSV or JSON lines download. CSV files start with a header row of column names, and cells a spreadsheet would run as a formula are prefixed with ' (importing the file removes it again); JSON lines hold one vendor per line as the API returns it. Filters narrow the export as they do the listing
+// @Tags vendors
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "File format" Enums(csv, jsonl) default(csv)
+// @Param filter query []string false "Filters of the form field<operator>value, e.g. name~acme" collectionFormat(multi)
+// @Success 200 {file} file
+// @Failure 400 {object} server.ErrorResponse "Invalid format or filter"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/export [get]
+func (s *EchoServer) ExportVendors(ctx echo.Context) error {
+	return writeExport(ctx, "vendors", transfer.Vendors, s.DB.ExportVendors)
+}
+
+// ImportVendors godoc
+// @Summary Import vendors
+// @Description Create and update vendors from a CSV or JSON lines file, as exported. Rows without vendor_id create a vendor, rows with one update the columns they give, leaving the rest unchanged. Headers that differ from the column names can be mapped with map=header=column. In atomic mode (the default) any failing row rolls back the whole import; in partial mode the good rows are kept. A dry run applies every row and then rolls back. Each row is reported by its line in the file.
+// @Tags vendors
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Produce  json
+// @Param file body string true "Rows to import"
+// @Param format query string false "File format, taken from the Content-Type when omitted" Enums(csv, jsonl)
+// @Param map query []string false "Header mappings of the form header=column" collectionFormat(multi)
+// @Param mode query string false "Import mode" Enums(atomic, partial) default(atomic)
+// @Param dry_run query bool false "Validate and report without keeping any change"
+// @Success 200 {object} server.ImportResponse "Every row succeeded"
+// @Success 207 {object} server.ImportResponse "At least one row failed"
+// @Failure 400 {object} server.ErrorResponse "Invalid format, map or dry_run"
+// @Failure 401 {object} server.ErrorResponse "Missing or invalid bearer token or API key"
+// @Failure 403 {object} server.ErrorResponse "Missing permission"
+// @Failure 415 {object} server.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} server.ErrorResponse "Unreadable header, empty file or too many rows"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /vendors/import [post]
+func (s *EchoServer) ImportVendors(ctx echo.Context) error {
+	return readImport(ctx, transfer.Vendors, s.DB.ImportVendors)
+}
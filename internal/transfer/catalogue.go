@@ -0,0 +1,66 @@
+package transfer
+
+import (
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// deletedAt is only ever present in JSON lines exports, always null.
+var deletedAt = []string{"deleted_at"}
+
+// Products are exported with their vendor's ID. On import a vendor column
+// holding the vendor's name may be given instead. Quantity on hand is only
+// read for new products, as their opening stock.
+//
+// Every codec carries the record's version, so re-importing an edited export
+// fails for rows changed in the meantime. Leaving it empty overwrites any
+// version.
+var Products = Codec[models.Product]{
+	Columns: []Column[models.Product]{
+		id("product_id", func(p *models.Product) *uuid.UUID { return &p.ProductID }),
+		text("name", func(p *models.Product) *string { return &p.Name }),
+		number("price", func(p *models.Product) *float64 { return &p.Price }),
+		id("vendor_id", func(p *models.Product) *uuid.UUID { return &p.VendorID }),
+		integer("quantity_on_hand", func(p *models.Product) *int { return &p.QuantityOnHand }),
+		integer("reorder_threshold", func(p *models.Product) *int { return &p.ReorderThreshold }),
+		integer("version", func(p *models.Product) *int { return &p.Version }),
+	},
+	References: []string{"vendor"},
+	Ignored:    deletedAt,
+}
+
+var Vendors = Codec[models.Vendor]{
+	Columns: []Column[models.Vendor]{
+		id("vendor_id", func(v *models.Vendor) *uuid.UUID { return &v.VendorID }),
+		text("name", func(v *models.Vendor) *string { return &v.Name }),
+		text("contact", func(v *models.Vendor) *string { return &v.Contact }),
+		text("phone", func(v *models.Vendor) *string { return &v.Phone }),
+		text("email", func(v *models.Vendor) *string { return &v.Email }),
+		text("address", func(v *models.Vendor) *string { return &v.Address }),
+		integer("version", func(v *models.Vendor) *int { return &v.Version }),
+	},
+	Ignored: deletedAt,
+}
+
+var Services = Codec[models.Service]{
+	Columns: []Column[models.Service]{
+		id("service_id", func(s *models.Service) *uuid.UUID { return &s.ServiceID }),
+		text("name", func(s *models.Service) *string { return &s.Name }),
+		number("price", func(s *models.Service) *float64 { return &s.Price }),
+		integer("version", func(s *models.Service) *int { return &s.Version }),
+	},
+	Ignored: deletedAt,
+}
+
+var Customers = Codec[models.Customer]{
+	Columns: []Column[models.Customer]{
+		id("customer_id", func(c *models.Customer) *uuid.UUID { return &c.CustomerID }),
+		text("first_name", func(c *models.Customer) *string { return &c.FirstName }),
+		text("last_name", func(c *models.Customer) *string { return &c.LastName }),
+		text("email", func(c *models.Customer) *string { return &c.Email }),
+		text("phone", func(c *models.Customer) *string { return &c.Phone }),
+		text("address", func(c *models.Customer) *string { return &c.Address }),
+		integer("version", func(c *models.Customer) *int { return &c.Version }),
+	},
+	Ignored: deletedAt,
+}
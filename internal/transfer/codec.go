@@ -0,0 +1,186 @@
+package transfer
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+	"github.com/johnifegwu/go-microservices/internal/models"
+)
+
+// Column maps one column of an import or export file onto a field of T.
+type Column[T any] struct {
+	Name string
+	Get  func(item *T) string
+	Set  func(item *T, value string) error
+}
+
+// Codec converts records of T to and from file rows.
+type Codec[T any] struct {
+	Columns []Column[T]
+	// References are import-only columns naming a related record that the
+	// importer resolves, such as a product's vendor by name.
+	References []string
+	// Ignored columns are accepted on import but not read, so a JSON lines
+	// export imports as is.
+	Ignored []string
+}
+
+// Header returns the column names in export order.
+func (c Codec[T]) Header() []string {
+	header := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		header[i] = column.Name
+	}
+	return header
+}
+
+// Encode returns the cells of item in export order.
+func (c Codec[T]) Encode(item *T) []string {
+	cells := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		cells[i] = column.Get(item)
+	}
+	return cells
+}
+
+// Decode builds the import row for record. Every cell that cannot be read is
+// reported, as is every column the codec does not know.
+func (c Codec[T]) Decode(record *Record) models.ImportRow[T] {
+	row := models.ImportRow[T]{Line: record.Line, Err: record.Err}
+	if record.Err != nil {
+		return row
+	}
+
+	names := make([]string, 0, len(record.Values))
+	for name := range record.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs dberrors.ValidationErrors
+	for _, name := range names {
+		value := strings.TrimSpace(record.Values[name])
+
+		if column, ok := c.column(name); ok {
+			if err := column.Set(&row.Item, value); err != nil {
+				errs = append(errs, dberrors.ValidationError{Field: name, Message: err.Error()})
+			}
+			row.Columns = append(row.Columns, name)
+			continue
+		}
+		if contains(c.References, name) {
+			if value != "" {
+				if row.References == nil {
+					row.References = make(map[string]string)
+				}
+				row.References[name] = value
+			}
+			continue
+		}
+		if !contains(c.Ignored, name) {
+			errs = append(errs, dberrors.ValidationError{Field: name, Message: "is not a known column"})
+		}
+	}
+
+	if len(errs) > 0 {
+		row.Err = errs
+	}
+	return row
+}
+
+func (c Codec[T]) column(name string) (Column[T], bool) {
+	for _, column := range c.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return Column[T]{}, false
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// text is a column holding a string field.
+func text[T any](name string, field func(*T) *string) Column[T] {
+	return Column[T]{
+		Name: name,
+		Get:  func(item *T) string { return *field(item) },
+		Set: func(item *T, value string) error {
+			*field(item) = value
+			return nil
+		},
+	}
+}
+
+// number is a column holding a decimal field. Empty cells read as zero.
+func number[T any](name string, field func(*T) *float64) Column[T] {
+	return Column[T]{
+		Name: name,
+		Get:  func(item *T) string { return strconv.FormatFloat(*field(item), 'f', -1, 64) },
+		Set: func(item *T, value string) error {
+			if value == "" {
+				return nil
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return errors.New("must be a number")
+			}
+			*field(item) = parsed
+			return nil
+		},
+	}
+}
+
+// integer is a column holding a whole number field. Empty cells read as zero.
+func integer[T any](name string, field func(*T) *int) Column[T] {
+	return Column[T]{
+		Name: name,
+		Get:  func(item *T) string { return strconv.Itoa(*field(item)) },
+		Set: func(item *T, value string) error {
+			if value == "" {
+				return nil
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.New("must be a whole number")
+			}
+			*field(item) = parsed
+			return nil
+		},
+	}
+}
+
+// id is a column holding a UUID field. Empty cells leave the ID unset, which
+// for a record's own ID means the row creates a new record.
+func id[T any](name string, field func(*T) *uuid.UUID) Column[T] {
+	return Column[T]{
+		Name: name,
+		Get: func(item *T) string {
+			if *field(item) == uuid.Nil {
+				return ""
+			}
+			return field(item).String()
+		},
+		Set: func(item *T, value string) error {
+			if value == "" {
+				return nil
+			}
+			parsed, err := uuid.Parse(value)
+			if err != nil {
+				return errors.New("must be a UUID")
+			}
+			*field(item) = parsed
+			return nil
+		},
+	}
+}
@@ -0,0 +1,303 @@
+// Package transfer reads and writes catalogue records as CSV or JSON lines
+// for bulk import and export.
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/johnifegwu/go-microservices/internal/dberrors"
+)
+
+// Supported formats.
+const (
+	CSV   = "csv"
+	JSONL = "jsonl"
+)
+
+// maxLineSize bounds a single JSON lines record.
+const maxLineSize = 1 << 20
+
+// ContentType returns the media type a file of format is served as.
+func ContentType(format string) string {
+	if format == JSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FormatOf returns the format of a body sent with contentType, or "" when the
+// media type is not one of ours.
+func FormatOf(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return CSV
+	case "application/x-ndjson", "application/jsonl", "application/jsonlines":
+		return JSONL
+	}
+	return ""
+}
+
+func checkFormat(format string) error {
+	if format != CSV && format != JSONL {
+		return &dberrors.InvalidQueryError{Parameter: "format", Message: fmt.Sprintf("%q is neither csv nor jsonl", format)}
+	}
+	return nil
+}
+
+// ParseMapping reads header mappings of the form "File header=column", which
+// let a file whose headers differ from our column names be imported as is.
+func ParseMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		at := strings.LastIndex(pair, "=")
+		if at < 1 || at == len(pair)-1 {
+			return nil, &dberrors.InvalidQueryError{Parameter: "map", Message: fmt.Sprintf("%q is not of the form header=column", pair)}
+		}
+		mapping[strings.TrimSpace(pair[:at])] = strings.TrimSpace(pair[at+1:])
+	}
+	return mapping, nil
+}
+
+// Record is one row of an import file keyed by column name. Line is where it
+// starts in the file. Err is set instead of Values when the row is malformed;
+// reading carries on with the next row.
+type Record struct {
+	Line   int
+	Values map[string]string
+	Err    error
+}
+
+// Reader yields the records of an import file one at a time.
+type Reader interface {
+	// Read returns the next record, or io.EOF after the last one.
+	Read() (*Record, error)
+}
+
+// NewReader reads r in format, renaming headers found in mapping.
+func NewReader(format string, r io.Reader, mapping map[string]string) (Reader, error) {
+	if err := checkFormat(format); err != nil {
+		return nil, err
+	}
+
+	if format == JSONL {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &jsonlReader{scanner: scanner, mapping: mapping}, nil
+	}
+	return &csvReader{reader: csv.NewReader(r), mapping: mapping}, nil
+}
+
+// column returns the column a file header stands for.
+func column(mapping map[string]string, header string) string {
+	header = strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))
+	if name, ok := mapping[header]; ok {
+		return name
+	}
+	return header
+}
+
+func rowError(message string) error {
+	return &dberrors.ValidationError{Field: "row", Message: message}
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	mapping map[string]string
+	columns []string
+}
+
+func (c *csvReader) Read() (*Record, error) {
+	if c.columns == nil {
+		header, err := c.reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return nil, &dberrors.ValidationError{Field: "header", Message: err.Error()}
+		}
+
+		seen := make(map[string]bool, len(header))
+		for _, h := range header {
+			name := column(c.mapping, h)
+			if seen[name] {
+				return nil, &dberrors.ValidationError{Field: "header", Message: fmt.Sprintf("column %q appears more than once", name)}
+			}
+			seen[name] = true
+			c.columns = append(c.columns, name)
+		}
+	}
+
+	fields, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &Record{Line: parseErr.StartLine, Err: rowError(parseErr.Err.Error())}, nil
+		}
+		return nil, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	values := make(map[string]string, len(fields))
+	for i, name := range c.columns {
+		values[name] = unquoteFormula(fields[i])
+	}
+	return &Record{Line: line, Values: values}, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	mapping map[string]string
+	line    int
+}
+
+func (j *jsonlReader) Read() (*Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := bytes.TrimSpace(j.scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		return j.record(text), nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &dberrors.ValidationError{Field: "row", Message: fmt.Sprintf("line %d is longer than %d bytes", j.line+1, maxLineSize)}
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// record decodes one JSON object, turning its values into the strings a
+// CSV cell would hold. Nulls are left out as if the key were absent.
+func (j *jsonlReader) record(text []byte) *Record {
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil || object == nil {
+		return &Record{Line: j.line, Err: rowError("is not a JSON object")}
+	}
+
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		name := column(j.mapping, key)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return &Record{Line: j.line, Err: rowError(fmt.Sprintf("%s must be a string, number or boolean", key))}
+		}
+	}
+	return &Record{Line: j.line, Values: values}
+}
+
+// Writer writes exported records. Output is buffered until Flush.
+type Writer[T any] interface {
+	Write(item *T) error
+	Flush() error
+}
+
+// NewWriter writes records of T to w in format. CSV files get a header row
+// of the codec's columns; JSON lines hold each record as the API returns it.
+func NewWriter[T any](format string, w io.Writer, codec Codec[T]) (Writer[T], error) {
+	if err := checkFormat(format); err != nil {
+		return nil, err
+	}
+
+	if format == JSONL {
+		buffer := bufio.NewWriter(w)
+		return &jsonlWriter[T]{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+	}
+
+	writer := &csvWriter[T]{writer: csv.NewWriter(w), codec: codec}
+	if err := writer.writer.Write(codec.Header()); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+type csvWriter[T any] struct {
+	writer *csv.Writer
+	codec  Codec[T]
+}
+
+func (c *csvWriter[T]) Write(item *T) error {
+	cells := c.codec.Encode(item)
+	for i, cell := range cells {
+		cells[i] = quoteFormula(cell)
+	}
+	return c.writer.Write(cells)
+}
+
+func (c *csvWriter[T]) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// formulaStarts are the characters that make a spreadsheet read a cell as a
+// formula.
+const formulaStarts = "=+-@\t\r"
+
+// isFormula reports whether a spreadsheet would evaluate cell, or would once
+// quoteFormula has been undone. Numbers such as -5 are left alone.
+func isFormula(cell string) bool {
+	if cell == "" {
+		return false
+	}
+	if cell[0] == '\'' {
+		return isFormula(cell[1:])
+	}
+	if !strings.ContainsRune(formulaStarts, rune(cell[0])) {
+		return false
+	}
+	_, err := strconv.ParseFloat(cell, 64)
+	return err != nil
+}
+
+// quoteFormula prefixes cells a spreadsheet would evaluate with ', so that
+// an exported name such as =HYPERLINK(...) opens as text.
+func quoteFormula(cell string) string {
+	if isFormula(cell) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unquoteFormula undoes quoteFormula, so exported files import unchanged.
+func unquoteFormula(cell string) string {
+	if strings.HasPrefix(cell, "'") && isFormula(cell[1:]) {
+		return cell[1:]
+	}
+	return cell
+}
+
+type jsonlWriter[T any] struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (j *jsonlWriter[T]) Write(item *T) error {
+	return j.encoder.Encode(item)
+}
+
+func (j *jsonlWriter[T]) Flush() error {
+	return j.buffer.Flush()
+}
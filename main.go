@@ -1,19 +1,161 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 
+	"github.com/johnifegwu/go-microservices/internal/config"
+	"github.com/johnifegwu/go-microservices/internal/events"
 	database "github.com/johnifegwu/go-microservices/internal/infrastructure"
+	"github.com/johnifegwu/go-microservices/internal/infrastructure/migrations"
 	"github.com/johnifegwu/go-microservices/internal/server"
 )
 
 func main() {
-	db, err := database.NewDatabaseClient()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+		cfg, err := config.Load(flags, os.Args[2:])
+		if err != nil {
+			log.Fatalf("migrate: %s", err)
+		}
+		if err := migrate(cfg, flags.Args()); err != nil {
+			log.Fatalf("migrate: %s", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	db, err := database.NewDatabaseClient(cfg)
 	if err != nil {
 		log.Fatalf("failed to initialize Database Client: %s", err)
 	}
-	srv := server.NewEchoServer(db)
-	if err := srv.Start(); err != nil {
-		log.Fatal(err.Error())
+	srv, err := server.NewEchoServer(cfg, db)
+	if err != nil {
+		db.Close()
+		log.Fatalf("failed to initialize server: %s", err)
+	}
+
+	// The relay keeps publishing until the server has drained, so events
+	// raised by the last requests still go out
+	relaying, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	var relay sync.WaitGroup
+	var publisher events.Publisher
+	if cfg.Features.Events {
+		publisher, err = events.NewPublisher(cfg.Events)
+		if err != nil {
+			db.Close()
+			log.Fatalf("failed to initialize events publisher: %s", err)
+		}
+		relay.Add(1)
+		go func() {
+			defer relay.Done()
+			events.NewRelay(db, publisher, cfg.Events).Run(relaying)
+		}()
+	}
+
+	// Docker and Kubernetes stop containers with SIGTERM
+	stopping, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	started := make(chan error, 1)
+	go func() {
+		started <- srv.Start()
+	}()
+
+	select {
+	case err := <-started:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			db.Close()
+			log.Fatal(err)
+		}
+	case <-stopping.Done():
+	}
+	stop()
+
+	log.Printf("shutting down, draining requests for up to %s", cfg.Server.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("requests did not drain: %s", err)
+	}
+	stopRelay()
+	relay.Wait()
+	if publisher != nil {
+		if err := publisher.Close(); err != nil {
+			log.Printf("failed to close the events publisher: %s", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		log.Printf("failed to close the database: %s", err)
+	}
+}
+
+// migrate runs the migrate subcommand, which takes the same configuration
+// flags as the server before its own arguments:
+//
+//	app migrate [flags] up [steps]
+//	app migrate [flags] down [steps]
+//	app migrate [flags] status
+func migrate(cfg *config.Config, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if command != "up" && command != "down" && command != "status" {
+		return fmt.Errorf("unknown command %q, expected up, down or status", command)
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("steps must be a positive number, got %q", args[1])
+		}
+		steps = n
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		return migrator.Up(ctx, steps)
+	case "down":
+		return migrator.Down(ctx, steps)
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, applied)
+		}
 	}
+	return nil
 }